@@ -1,8 +1,14 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	
@@ -10,41 +16,143 @@ import (
 	"github.com/wtppaul/course-service/internal/config"
 	"github.com/wtppaul/course-service/internal/database"
 	"github.com/wtppaul/course-service/internal/handler"
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/middleware"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/observability"
+	"github.com/wtppaul/course-service/internal/outbox"
+	"github.com/wtppaul/course-service/internal/problem"
 	"github.com/wtppaul/course-service/internal/redis"
 	"github.com/wtppaul/course-service/internal/repository"
 	"github.com/wtppaul/course-service/internal/routes"
+	"github.com/wtppaul/course-service/internal/service"
 )
 
 func main() {
-	// 0️⃣ Kustom Validator (Saat ini tidak ada, bisa ditambahkan nanti)
-	// if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-	//     v.RegisterValidation(...)
-	// }
+	// 0️⃣ Validator: nama field di error validasi mengikuti tag JSON
+	problem.RegisterValidatorTagNames()
 
-	// 1️⃣ Load environment variables
-	config.Load()
-	
-	// 2️⃣ Setup database & redis
-	database.InitDB()
-	redis.InitRedis()
+	// 1️⃣ Load konfigurasi (env + .env) & logger JSON
+	cfg, err := config.Load()
+	logging.Setup(cfg.Log.Level)
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+
+	// Subcommand: course-service migrate up|down|status|to <version>
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := cfg.Database.Validate(); err != nil {
+			logging.Fatal("invalid configuration", "error", err)
+		}
+		runMigrate(cfg.Database, os.Args[2:])
+		return
+	}
+
+	// Fail fast: semua kesalahan konfigurasi dilaporkan sebelum connect ke mana pun
+	if err := cfg.Validate(); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	signingKeys, err := middleware.ParseSigningKeys(cfg.Auth.SigningKeys.Value())
+	if err != nil {
+		logging.Fatal("invalid INTERNAL_API_KEYS", "error", err)
+	}
+	statusPolicy, err := models.NewStatusPolicy(cfg.Course.StatusTransitionRoles)
+	if err != nil {
+		logging.Fatal("invalid COURSE_STATUS_TRANSITION_ROLES", "error", err)
+	}
+	slog.Debug("configuration loaded", "config", cfg) // Secret otomatis disamarkan
+
+	// Context yang dibatalkan saat SIGINT/SIGTERM (misal dari Kubernetes)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 2️⃣ Observability: metrik Prometheus & tracing OpenTelemetry.
+	// Span diekspor via OTLP jika OTEL_EXPORTER_OTLP_ENDPOINT di-set;
+	// tanpa itu, trace context dari gateway tetap diteruskan.
+	var metrics *observability.Metrics
+	if cfg.Features.Metrics {
+		metrics = observability.NewMetrics()
+	}
 
-	// 3️⃣ Hapus Inisialisasi WebAuthn (Tidak relevan)
+	tracingCfg := observability.TracingConfig{ServiceName: "course-service", SampleRatio: cfg.Tracing.SampleRatio}
+	if cfg.Tracing.OTLPEndpoint != "" {
+		if tracingCfg.Exporter, err = observability.NewOTLPExporter(ctx); err != nil {
+			logging.Fatal("failed to create OTLP exporter", "error", err)
+		}
+	}
+	shutdownTracing, err := observability.SetupTracing(ctx, tracingCfg)
+	if err != nil {
+		logging.Fatal("failed to set up tracing", "error", err)
+	}
+
+	// 2️⃣b Setup database & redis (lalu pasang instrumentasi)
+	db := database.InitDB(cfg.Database, cfg.Features.AutoMigrate)
+	redisClient := redis.InitRedis(cfg.Redis)
+
+	if err := observability.InstrumentGORM(db, metrics); err != nil {
+		logging.Fatal("failed to instrument GORM", "error", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		metrics.RegisterDBStats(sqlDB, "course")
+	}
+	observability.InstrumentRedis(redisClient, metrics)
+
+	// 3️⃣ Outbox relay: kirim event domain ke Redis Streams (at-least-once).
+	// Bisa dimatikan jika relay dijalankan sebagai deployment terpisah.
+	relayDone := make(chan struct{})
+	if cfg.Features.OutboxRelay {
+		relay := outbox.NewRelay(db, redisClient, outbox.Config{
+			Stream:       cfg.Outbox.Stream,
+			MaxLen:       cfg.Outbox.MaxLen,
+			BatchSize:    cfg.Outbox.BatchSize,
+			PollInterval: cfg.Outbox.PollInterval,
+		})
+		go func() {
+			defer close(relayDone)
+			relay.Run(ctx)
+		}()
+	} else {
+		close(relayDone)
+	}
 
 	// 4️⃣ Init Gin
-	router := gin.Default()
+	router := gin.New() // Logger & recovery dipasang di routes (slog)
 	router.SetTrustedProxies(nil) // Atur trusted proxies Anda
 
 	// 5️⃣ Setup handlers & services (Versi Course-service)
 	
 	// A. Inisialisasi Repository (Dependensi: Database)
-	courseRepo := repository.NewCourseRepository(database.DB)
+	courseRepo := repository.NewCourseRepository(db)
+
+	// A2. Cache Redis (read-through) di atas repository (CACHE_ENABLED).
+	// Dekorator yang sama dipasang pada repository di dalam transaksi.
+	var decorators []repository.Decorator
+	if cfg.Features.Cache {
+		decorators = append(decorators, func(r repository.ICourseRepository) repository.ICourseRepository {
+			return repository.NewCachedCourseRepository(r, redisClient, cfg.Cache.TTL)
+		})
+	}
+	for _, decorate := range decorators {
+		courseRepo = decorate(courseRepo)
+	}
 
-	// B. Inisialisasi Handler (Dependensi: Repository)
-	courseHandler := handler.NewCourseHandler(courseRepo)
+	// B. Aturan readiness sebelum course boleh diajukan ke review
+	readiness := models.ReadinessConfig{MinChapters: cfg.Course.MinChapters}
+
+	// B2. Service (aturan bisnis) + unit of work untuk operasi multi-langkah
+	uow := repository.NewUnitOfWork(db, decorators...)
+	courseService := service.NewCourseService(courseRepo, uow, statusPolicy, readiness, metrics)
+
+	// C. Inisialisasi Handler (Dependensi: Service & Repository)
+	courseHandler := handler.NewCourseHandler(courseService)
 	
-	// (Jika Anda meng-upgrade /health, inisialisasi health handler di sini)
-	// healthService := service.NewHealthService()
-	// healthHandler := handler.NewHealthHandler(healthService)
+	// C2. Autentikasi signature gateway & otorisasi per rute
+	// (role dari gateway + kepemilikan course)
+	internalAuth := middleware.InternalAuthMiddleware(signingKeys, cfg.Auth.SignatureWindow, cfg.Auth.MaxBodyBytes)
+	authorizer := middleware.NewAuthorizer(courseRepo)
+
+	// D. Probe liveness & readiness (ping Postgres & Redis)
+	healthHandler := handler.NewHealthHandler(db, redisClient, cfg.Server.ReadinessTimeout)
 
 
 	// 6️⃣ Centralized route setup
@@ -52,11 +160,63 @@ func main() {
 	routes.SetupCourseRoutes(
 		router,
 		courseHandler, 
-		// healthHandler, // (Tambahkan ini jika Anda upgrade /health)
+		healthHandler,
+		internalAuth,
+		authorizer,
+		metrics,
+		redisClient, // Untuk Idempotency-Key
 	)
 
-	// 7️⃣ Run server
-	port := config.GetEnv("SERVER_PORT", "8081")
-	fmt.Println("🚀 Course-service running at http://localhost:" + port)
-	log.Fatal(router.Run(":" + port))
+	// 7️⃣ Run server (graceful shutdown)
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("course-service listening", "port", cfg.Server.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		logging.Fatal("server failed", "error", err)
+	case <-ctx.Done():
+	}
+	stop() // Sinyal kedua = keluar paksa
+
+	// 8️⃣ Shutdown: readyz 503 dulu, lalu tunggu request yang berjalan
+	// selesai (maks SHUTDOWN_TIMEOUT), hentikan relay, lalu tutup koneksi
+	slog.Info("shutting down, draining in-flight requests", "timeout", cfg.Server.ShutdownTimeout.String())
+	healthHandler.MarkShuttingDown()
+	time.Sleep(cfg.Server.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("drain timeout exceeded, closing remaining connections", "error", err)
+		server.Close()
+	}
+
+	select {
+	case <-relayDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("outbox relay did not stop in time")
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	redisClient.Close()
+	slog.Info("course-service stopped")
 }
\ No newline at end of file
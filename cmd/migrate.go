@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/wtppaul/course-service/internal/config"
+	"github.com/wtppaul/course-service/internal/database"
+	"github.com/wtppaul/course-service/internal/logging"
+)
+
+const migrateUsage = `Usage: course-service migrate <command>
+
+Commands:
+  up            Jalankan semua migrasi yang tertunda
+  down [n]      Batalkan n migrasi terakhir (default 1)
+  status        Tampilkan status setiap migrasi
+  to <version>  Naik/turun ke versi tertentu (0 = kosongkan skema)`
+
+// runMigrate menangani subcommand 'migrate'
+func runMigrate(cfg config.DatabaseConfig, args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	migrator, err := database.NewMigrator(database.Connect(cfg))
+	if err != nil {
+		logging.Fatal("failed to load migrations", "error", err)
+	}
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				logging.Fatal("invalid number of steps", "value", args[1])
+			}
+		}
+		err = migrator.Down(ctx, steps)
+
+	case "to":
+		if len(args) < 2 {
+			logging.Fatal("missing target version")
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			logging.Fatal("invalid version", "value", args[1])
+		}
+		err = migrator.To(ctx, version)
+
+	case "status":
+		statuses, statusErr := migrator.Status(ctx)
+		if statusErr != nil {
+			logging.Fatal("failed to read migration status", "error", statusErr)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, applied)
+		}
+		return
+
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		logging.Fatal("migration failed", "error", err)
+	}
+	slog.Info("migration done")
+}
@@ -0,0 +1,103 @@
+// Package apperr berisi tipe error aplikasi yang dipakai bersama oleh
+// repository, service, middleware & handler. Package ini tidak bergantung
+// pada package lain di service ini, sehingga lapisan HTTP tidak perlu
+// mengimpor repository hanya untuk membuat error.
+package apperr
+
+// Kind mengelompokkan error domain. Status HTTP ditentukan dari Kind
+// di satu tempat (package problem), bukan di setiap handler.
+type Kind string
+
+const (
+	KindInvalidInput         Kind = "INVALID_INPUT"         // 400
+	KindUnauthenticated      Kind = "UNAUTHENTICATED"       // 401
+	KindForbidden            Kind = "FORBIDDEN"             // 403 (termasuk bukan pemilik)
+	KindNotFound             Kind = "NOT_FOUND"             // 404
+	KindConflict             Kind = "CONFLICT"              // 409
+	KindInvalidTransition    Kind = "INVALID_TRANSITION"    // 409
+	KindPreconditionFailed   Kind = "PRECONDITION_FAILED"   // 412 (versi usang)
+	KindPayloadTooLarge      Kind = "PAYLOAD_TOO_LARGE"     // 413
+	KindUnprocessable        Kind = "UNPROCESSABLE"         // 422 (aturan bisnis)
+	KindPreconditionRequired Kind = "PRECONDITION_REQUIRED" // 428
+	KindInternal             Kind = "INTERNAL"              // 500
+)
+
+// Error adalah error domain bertipe.
+//   - Code: kode stabil untuk mesin (dipakai BFF; JANGAN diganti setelah rilis)
+//   - Message: penjelasan bahasa Inggris untuk log; teks respons diambil
+//     dari katalog pesan berdasarkan Code
+//   - Params: nilai untuk placeholder pesan sekaligus field tambahan di respons
+//   - Fields: detail validasi per field
+//
+// Sentinel (repository.ErrCourseNotFound, dll.) dibandingkan dengan errors.Is
+// berdasarkan Code, jadi salinan hasil With/Wrap tetap cocok.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Params  map[string]any
+	Fields  []FieldError
+	Err     error // Penyebab (opsional)
+}
+
+// FieldError adalah satu pelanggaran validasi pada field request
+type FieldError struct {
+	Field   string `json:"field"`             // Path JSON, misal: items[0].lessonId
+	Code    string `json:"code"`              // Misal: REQUIRED, MIN, INVALID_TYPE
+	Param   string `json:"param,omitempty"`   // Misal: batas untuk MIN/MAX
+	Message string `json:"message,omitempty"` // Diisi dari katalog saat respons dibuat
+}
+
+// NewError membuat error domain (biasanya untuk sentinel level package)
+func NewError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is: dua Error dianggap sama jika Code-nya sama
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// With mengembalikan salinan error dengan satu param tambahan
+func (e *Error) With(key string, value any) *Error {
+	clone := e.clone()
+	clone.Params[key] = value
+	return clone
+}
+
+// WithFields mengembalikan salinan error dengan detail validasi per field
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	clone := e.clone()
+	clone.Fields = append(clone.Fields, fields...)
+	return clone
+}
+
+// Wrap mengembalikan salinan error dengan penyebab 'err' (tetap bisa
+// dicek dengan errors.Is, misal gorm.ErrRecordNotFound)
+func (e *Error) Wrap(err error) *Error {
+	clone := e.clone()
+	clone.Err = err
+	return clone
+}
+
+func (e *Error) clone() *Error {
+	clone := *e
+	clone.Params = make(map[string]any, len(e.Params)+1)
+	for k, v := range e.Params {
+		clone.Params[k] = v
+	}
+	clone.Fields = append([]FieldError(nil), e.Fields...)
+	return &clone
+}
@@ -0,0 +1,39 @@
+package apperr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorCopiesMatchSentinel(t *testing.T) {
+	sentinel := NewError(KindNotFound, "COURSE_NOT_FOUND", "course not found")
+	cause := errors.New("record not found")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "with param", err: sentinel.With("courseId", "x")},
+		{name: "with fields", err: sentinel.WithFields(FieldError{Field: "title", Code: "REQUIRED"})},
+		{name: "wrapped cause", err: sentinel.Wrap(cause)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, sentinel) {
+				t.Fatalf("errors.Is(%v, sentinel) = false", tt.err)
+			}
+			if errors.Is(tt.err, NewError(KindNotFound, "LESSON_NOT_FOUND", "lesson not found")) {
+				t.Fatal("copies must only match sentinels with the same code")
+			}
+		})
+	}
+
+	// Salinan tidak boleh mengubah sentinel
+	if len(sentinel.Params) != 0 || len(sentinel.Fields) != 0 || sentinel.Err != nil {
+		t.Fatalf("sentinel mutated: %+v", sentinel)
+	}
+	if !errors.Is(sentinel.Wrap(cause), cause) {
+		t.Fatal("wrapped cause is not in the error chain")
+	}
+}
@@ -2,13 +2,325 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-func Load() {
+// Config adalah seluruh konfigurasi service, dibaca sekali saat startup
+// lalu diteruskan secara eksplisit ke constructor (bukan dibaca dari env
+// di dalam package lain)
+type Config struct {
+	Server   ServerConfig
+	Database DatabaseConfig
+	Redis    RedisConfig
+	Auth     AuthConfig
+	Cache    CacheConfig
+	Course   CourseConfig
+	Outbox   OutboxConfig
+	Tracing  TracingConfig
+	Log      LogConfig
+	Features FeatureConfig
+}
+
+// ServerConfig mengatur HTTP server, probe & graceful shutdown
+type ServerConfig struct {
+	Port              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration // Batas waktu drain request yang berjalan
+	DrainDelay        time.Duration // Jeda antara readyz=503 dan menutup listener
+	ReadinessTimeout  time.Duration // Batas waktu ping per dependency di /readyz
+}
+
+// DatabaseConfig mengatur koneksi & pool Postgres
+type DatabaseConfig struct {
+	Host            string
+	Port            string
+	User            string
+	Password        Secret
+	Name            string
+	SSLMode         string
+	ConnectTimeout  time.Duration
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// RedisConfig mengatur koneksi Redis (cache, idempotency, outbox stream)
+type RedisConfig struct {
+	Host         string
+	Port         string
+	Username     string
+	Password     Secret
+	DB           int
+	TLS          bool
+	PoolSize     int // 0 = default go-redis (10 per CPU)
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// AuthConfig mengatur verifikasi request bertanda tangan dari gateway
+type AuthConfig struct {
+	SigningKeys     Secret // "kid1:secret1,kid2:secret2" (di-parse oleh middleware)
+	SignatureWindow time.Duration
+	MaxBodyBytes    int64 // Batas body yang dibaca sebelum signature diverifikasi
+}
+
+// CacheConfig mengatur cache read-through course
+type CacheConfig struct {
+	TTL time.Duration
+}
+
+// CourseConfig mengatur aturan domain course
+type CourseConfig struct {
+	StatusTransitionRoles string // Override role per transisi (di-parse oleh models)
+	MinChapters           int    // Minimal chapter sebelum boleh diajukan ke review
+}
+
+// OutboxConfig mengatur relay outbox ke Redis Streams
+type OutboxConfig struct {
+	Stream       string
+	MaxLen       int64
+	BatchSize    int
+	PollInterval time.Duration
+}
+
+// TracingConfig mengatur OpenTelemetry
+type TracingConfig struct {
+	SampleRatio  float64
+	OTLPEndpoint string // Kosong = span tidak diekspor
+}
+
+// LogConfig mengatur logger JSON
+type LogConfig struct {
+	Level string
+}
+
+// FeatureConfig berisi toggle fitur. Semua aktif secara default.
+type FeatureConfig struct {
+	AutoMigrate bool // Jalankan migrasi tertunda saat startup
+	Cache       bool // Cache Redis di atas repository
+	OutboxRelay bool // Relay outbox di proses ini (matikan jika relay dijalankan terpisah)
+	Metrics     bool // Endpoint /metrics
+}
+
+// Load membaca .env (jika ada) lalu environment variable ke Config.
+// Nilai yang tidak bisa di-parse dikumpulkan jadi satu error; Config
+// tetap dikembalikan (dengan default untuk nilai yang gagal) agar logger
+// bisa dipasang sebelum error dilaporkan.
+func Load() (*Config, error) {
 	_ = godotenv.Load()
+
+	var r envReader
+	cfg := &Config{
+		Server: ServerConfig{
+			Port:              r.string("SERVER_PORT", "8081"),
+			ReadHeaderTimeout: r.duration("SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+			ReadTimeout:       r.duration("SERVER_READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:      r.duration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       r.duration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+			ShutdownTimeout:   r.duration("SHUTDOWN_TIMEOUT", 20*time.Second),
+			DrainDelay:        r.duration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+			ReadinessTimeout:  r.duration("READINESS_TIMEOUT", 2*time.Second),
+		},
+		Database: DatabaseConfig{
+			Host:            r.string("DATABASE_HOST", ""),
+			Port:            r.string("DATABASE_PORT", "5432"),
+			User:            r.string("DATABASE_USER", ""),
+			Password:        Secret(r.string("DATABASE_PASSWORD", "")),
+			Name:            r.string("DATABASE_NAME", ""),
+			SSLMode:         r.string("DATABASE_SSL_MODE", "prefer"),
+			ConnectTimeout:  r.duration("DATABASE_CONNECT_TIMEOUT", 5*time.Second),
+			MaxOpenConns:    r.int("DATABASE_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    r.int("DATABASE_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: r.duration("DATABASE_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime: r.duration("DATABASE_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		},
+		Redis: RedisConfig{
+			Host:         r.string("REDIS_HOST", ""),
+			Port:         r.string("REDIS_PORT", "6379"),
+			Username:     r.string("REDIS_USERNAME", ""),
+			Password:     Secret(r.string("REDIS_PASSWORD", "")),
+			DB:           r.int("REDIS_DB", 0),
+			TLS:          r.bool("REDIS_TLS", false),
+			PoolSize:     r.int("REDIS_POOL_SIZE", 0),
+			DialTimeout:  r.duration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+			ReadTimeout:  r.duration("REDIS_READ_TIMEOUT", 3*time.Second),
+			WriteTimeout: r.duration("REDIS_WRITE_TIMEOUT", 3*time.Second),
+		},
+		Auth: AuthConfig{
+			SigningKeys:     Secret(r.string("INTERNAL_API_KEYS", "")),
+			SignatureWindow: r.duration("INTERNAL_SIGNATURE_WINDOW", 5*time.Minute),
+			MaxBodyBytes:    int64(r.int("INTERNAL_MAX_BODY_BYTES", 1<<20)),
+		},
+		Cache: CacheConfig{
+			TTL: r.duration("CACHE_TTL", 5*time.Minute),
+		},
+		Course: CourseConfig{
+			StatusTransitionRoles: r.string("COURSE_STATUS_TRANSITION_ROLES", ""),
+			MinChapters:           r.int("COURSE_MIN_CHAPTERS", 1),
+		},
+		Outbox: OutboxConfig{
+			Stream:       r.string("OUTBOX_STREAM", "course-service:events"),
+			MaxLen:       int64(r.int("OUTBOX_MAX_LEN", 100000)),
+			BatchSize:    r.int("OUTBOX_BATCH_SIZE", 100),
+			PollInterval: r.duration("OUTBOX_POLL_INTERVAL", time.Second),
+		},
+		Tracing: TracingConfig{
+			SampleRatio:  r.float("TRACING_SAMPLE_RATIO", 1),
+			OTLPEndpoint: r.string("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		},
+		Log: LogConfig{
+			Level: r.string("LOG_LEVEL", "info"),
+		},
+		Features: FeatureConfig{
+			AutoMigrate: r.bool("DB_AUTO_MIGRATE", true),
+			Cache:       r.bool("CACHE_ENABLED", true),
+			OutboxRelay: r.bool("OUTBOX_RELAY_ENABLED", true),
+			Metrics:     r.bool("METRICS_ENABLED", true),
+		},
+	}
+
+	return cfg, errors.Join(r.errs...)
+}
+
+// Validate memeriksa konfigurasi untuk menjalankan server. Semua masalah
+// dilaporkan sekaligus agar tidak perlu restart berulang kali.
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Server.Validate(),
+		c.Database.Validate(),
+		c.Redis.Validate(),
+		c.Auth.Validate(),
+		c.validateCache(),
+		c.Course.Validate(),
+		c.Outbox.Validate(),
+		c.Tracing.Validate(),
+		c.Log.Validate(),
+	)
+}
+
+func (c ServerConfig) Validate() error {
+	var v validator
+	v.check(validPort(c.Port), "SERVER_PORT must be a port number (1-65535)")
+	v.check(c.ReadHeaderTimeout > 0, "SERVER_READ_HEADER_TIMEOUT must be > 0")
+	v.check(c.ReadTimeout >= 0, "SERVER_READ_TIMEOUT must be >= 0")
+	v.check(c.WriteTimeout >= 0, "SERVER_WRITE_TIMEOUT must be >= 0")
+	v.check(c.IdleTimeout >= 0, "SERVER_IDLE_TIMEOUT must be >= 0")
+	v.check(c.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be > 0")
+	v.check(c.DrainDelay >= 0, "SHUTDOWN_DRAIN_DELAY must be >= 0")
+	v.check(c.ReadinessTimeout > 0, "READINESS_TIMEOUT must be > 0")
+	return v.err()
+}
+
+// Validate dipakai juga oleh subcommand migrate (yang hanya butuh database)
+func (c DatabaseConfig) Validate() error {
+	var v validator
+	v.check(c.Host != "", "DATABASE_HOST is required")
+	v.check(validPort(c.Port), "DATABASE_PORT must be a port number (1-65535)")
+	v.check(c.User != "", "DATABASE_USER is required")
+	v.check(c.Name != "", "DATABASE_NAME is required")
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		v.check(false, "DATABASE_SSL_MODE must be one of disable, allow, prefer, require, verify-ca, verify-full")
+	}
+	v.check(c.ConnectTimeout >= time.Second, "DATABASE_CONNECT_TIMEOUT must be >= 1s")
+	v.check(c.MaxOpenConns > 0, "DATABASE_MAX_OPEN_CONNS must be > 0")
+	v.check(c.MaxIdleConns >= 0 && c.MaxIdleConns <= c.MaxOpenConns, "DATABASE_MAX_IDLE_CONNS must be between 0 and DATABASE_MAX_OPEN_CONNS")
+	v.check(c.ConnMaxLifetime >= 0, "DATABASE_CONN_MAX_LIFETIME must be >= 0")
+	v.check(c.ConnMaxIdleTime >= 0, "DATABASE_CONN_MAX_IDLE_TIME must be >= 0")
+	return v.err()
+}
+
+// DSN membangun connection string Postgres (berisi password: jangan di-log)
+func (c DatabaseConfig) DSN() string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, c.Password.Value()),
+		Host:   net.JoinHostPort(c.Host, c.Port),
+		Path:   "/" + c.Name,
+	}
+	query := url.Values{}
+	query.Set("sslmode", c.SSLMode)
+	query.Set("connect_timeout", strconv.Itoa(int(c.ConnectTimeout/time.Second)))
+	dsn.RawQuery = query.Encode()
+	return dsn.String()
+}
+
+func (c RedisConfig) Validate() error {
+	var v validator
+	v.check(c.Host != "", "REDIS_HOST is required")
+	v.check(validPort(c.Port), "REDIS_PORT must be a port number (1-65535)")
+	v.check(c.DB >= 0, "REDIS_DB must be >= 0")
+	v.check(c.PoolSize >= 0, "REDIS_POOL_SIZE must be >= 0")
+	v.check(c.DialTimeout > 0, "REDIS_DIAL_TIMEOUT must be > 0")
+	v.check(c.ReadTimeout > 0, "REDIS_READ_TIMEOUT must be > 0")
+	v.check(c.WriteTimeout > 0, "REDIS_WRITE_TIMEOUT must be > 0")
+	return v.err()
+}
+
+// Addr adalah alamat host:port Redis
+func (c RedisConfig) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+func (c AuthConfig) Validate() error {
+	var v validator
+	// Tanpa key, tidak ada request yang boleh masuk: gagal saat startup
+	v.check(c.SigningKeys != "", "INTERNAL_API_KEYS is required")
+	v.check(c.SignatureWindow > 0, "INTERNAL_SIGNATURE_WINDOW must be > 0")
+	v.check(c.MaxBodyBytes > 0, "INTERNAL_MAX_BODY_BYTES must be > 0")
+	return v.err()
+}
+
+func (c *Config) validateCache() error {
+	var v validator
+	v.check(!c.Features.Cache || c.Cache.TTL > 0, "CACHE_TTL must be > 0 (set CACHE_ENABLED=false to disable the cache)")
+	return v.err()
+}
+
+func (c CourseConfig) Validate() error {
+	var v validator
+	v.check(c.MinChapters >= 1, "COURSE_MIN_CHAPTERS must be >= 1")
+	return v.err()
+}
+
+func (c OutboxConfig) Validate() error {
+	var v validator
+	v.check(c.Stream != "", "OUTBOX_STREAM is required")
+	v.check(c.MaxLen >= 0, "OUTBOX_MAX_LEN must be >= 0")
+	v.check(c.BatchSize > 0, "OUTBOX_BATCH_SIZE must be > 0")
+	v.check(c.PollInterval > 0, "OUTBOX_POLL_INTERVAL must be > 0")
+	return v.err()
+}
+
+func (c TracingConfig) Validate() error {
+	var v validator
+	v.check(c.SampleRatio >= 0 && c.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	return v.err()
+}
+
+func (c LogConfig) Validate() error {
+	var v validator
+	switch strings.ToLower(c.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		v.check(false, "LOG_LEVEL must be one of debug, info, warn, error")
+	}
+	return v.err()
 }
 
 // GetEnv returns env value or default if missing
@@ -19,3 +331,84 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return val
 }
+
+// envReader membaca env bertipe dan mengumpulkan error parse
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) string(key, def string) string {
+	return strings.TrimSpace(GetEnv(key, def))
+}
+
+func (r *envReader) int(key string, def int) int {
+	raw := r.string(key, "")
+	if raw == "" {
+		return def
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: invalid integer %q", key, raw))
+		return def
+	}
+	return val
+}
+
+func (r *envReader) float(key string, def float64) float64 {
+	raw := r.string(key, "")
+	if raw == "" {
+		return def
+	}
+	val, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: invalid number %q", key, raw))
+		return def
+	}
+	return val
+}
+
+func (r *envReader) bool(key string, def bool) bool {
+	raw := r.string(key, "")
+	if raw == "" {
+		return def
+	}
+	val, err := strconv.ParseBool(raw)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: invalid boolean %q", key, raw))
+		return def
+	}
+	return val
+}
+
+func (r *envReader) duration(key string, def time.Duration) time.Duration {
+	raw := r.string(key, "")
+	if raw == "" {
+		return def
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: invalid duration %q (example: 30s, 5m)", key, raw))
+		return def
+	}
+	return val
+}
+
+// validator mengumpulkan pelanggaran aturan konfigurasi
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, msg string) {
+	if !ok {
+		v.errs = append(v.errs, errors.New(msg))
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig adalah konfigurasi minimal yang lolos Validate
+func validConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8081",
+			ReadHeaderTimeout: 10 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			ReadinessTimeout:  2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:           "postgres",
+			Port:           "5432",
+			User:           "course",
+			Password:       "db-password-123",
+			Name:           "course",
+			SSLMode:        "prefer",
+			ConnectTimeout: 5 * time.Second,
+			MaxOpenConns:   25,
+			MaxIdleConns:   10,
+		},
+		Redis: RedisConfig{
+			Host:         "redis",
+			Port:         "6379",
+			Password:     "redis-password-456",
+			DialTimeout:  5 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+		},
+		Auth: AuthConfig{
+			SigningKeys:     "bff-1:signing-key-789",
+			SignatureWindow: 5 * time.Minute,
+			MaxBodyBytes:    1 << 20,
+		},
+		Cache:    CacheConfig{TTL: 5 * time.Minute},
+		Course:   CourseConfig{MinChapters: 1},
+		Outbox:   OutboxConfig{Stream: "course-service:events", BatchSize: 100, PollInterval: time.Second},
+		Tracing:  TracingConfig{SampleRatio: 1},
+		Log:      LogConfig{Level: "info"},
+		Features: FeatureConfig{Cache: true},
+	}
+}
+
+func TestSecretIsRedacted(t *testing.T) {
+	cfg := validConfig()
+	secrets := []string{cfg.Database.Password.Value(), cfg.Redis.Password.Value(), cfg.Auth.SigningKeys.Value()}
+
+	jsonLog := func(args ...any) string {
+		var buf bytes.Buffer
+		slog.New(slog.NewJSONHandler(&buf, nil)).Info("config loaded", args...)
+		return buf.String()
+	}
+	textLog := func(args ...any) string {
+		var buf bytes.Buffer
+		slog.New(slog.NewTextHandler(&buf, nil)).Info("config loaded", args...)
+		return buf.String()
+	}
+	marshal := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{name: "fmt %v", output: fmt.Sprintf("%v", cfg)},
+		{name: "fmt %+v", output: fmt.Sprintf("%+v", cfg)},
+		{name: "fmt %#v", output: fmt.Sprintf("%#v", cfg)},
+		{name: "fmt %s on secret", output: fmt.Sprintf("%s", cfg.Database.Password)},
+		{name: "json.Marshal", output: marshal(cfg)},
+		{name: "slog JSON attr", output: jsonLog("password", cfg.Database.Password)},
+		{name: "slog JSON struct", output: jsonLog("config", cfg)},
+		{name: "slog text attr", output: textLog("keys", cfg.Auth.SigningKeys)},
+		{name: "slog text struct", output: textLog("redis", cfg.Redis)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, secret := range secrets {
+				if strings.Contains(tt.output, secret) {
+					t.Fatalf("output leaks %q: %s", secret, tt.output)
+				}
+			}
+			if !strings.Contains(tt.output, redacted) {
+				t.Fatalf("output has no %s marker: %s", redacted, tt.output)
+			}
+		})
+	}
+
+	// Nilai asli tetap bisa dipakai (misal untuk DSN)
+	if !strings.Contains(cfg.Database.DSN(), cfg.Database.Password.Value()) {
+		t.Fatal("DSN does not carry the real password")
+	}
+	// Secret kosong tidak disamarkan (terlihat bahwa memang belum di-set)
+	if got := fmt.Sprintf("%v|%#v|%s", Secret(""), Secret(""), marshal(Secret(""))); got != `|""|""` {
+		t.Fatalf("empty secret = %s", got)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *Config)
+		want   []string // Semua pesan harus ada di satu error
+	}{
+		{name: "valid", mutate: func(c *Config) {}},
+		{
+			name: "one field",
+			mutate: func(c *Config) {
+				c.Server.Port = "0"
+			},
+			want: []string{"SERVER_PORT"},
+		},
+		{
+			name: "fields across sections",
+			mutate: func(c *Config) {
+				c.Database.Host = ""
+				c.Database.SSLMode = "sometimes"
+				c.Redis.Port = "redis"
+				c.Auth.SigningKeys = ""
+				c.Tracing.SampleRatio = 2
+				c.Log.Level = "verbose"
+			},
+			want: []string{"DATABASE_HOST", "DATABASE_SSL_MODE", "REDIS_PORT", "INTERNAL_API_KEYS", "TRACING_SAMPLE_RATIO", "LOG_LEVEL"},
+		},
+		{
+			name: "cache TTL only matters when the cache is enabled",
+			mutate: func(c *Config) {
+				c.Cache.TTL = 0
+				c.Features.Cache = false
+			},
+		},
+		{
+			name: "cache TTL required when enabled",
+			mutate: func(c *Config) {
+				c.Cache.TTL = 0
+			},
+			want: []string{"CACHE_TTL"},
+		},
+		{
+			name: "idle connections above the pool",
+			mutate: func(c *Config) {
+				c.Database.MaxIdleConns = c.Database.MaxOpenConns + 1
+				c.Course.MinChapters = 0
+			},
+			want: []string{"DATABASE_MAX_IDLE_CONNS", "COURSE_MIN_CHAPTERS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %v", tt.want)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Validate() reported %d problems, want %d:\n%v", len(lines), len(tt.want), err)
+			}
+			for _, field := range tt.want {
+				if !strings.Contains(err.Error(), field) {
+					t.Fatalf("Validate() does not mention %s:\n%v", field, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadCollectsParseErrors(t *testing.T) {
+	t.Setenv("SERVER_READ_TIMEOUT", "30")
+	t.Setenv("DATABASE_MAX_OPEN_CONNS", "many")
+	t.Setenv("REDIS_TLS", "maybe")
+	t.Setenv("TRACING_SAMPLE_RATIO", "half")
+
+	cfg, err := Load()
+	if cfg == nil {
+		t.Fatal("Load() returned no config")
+	}
+	for _, key := range []string{"SERVER_READ_TIMEOUT", "DATABASE_MAX_OPEN_CONNS", "REDIS_TLS", "TRACING_SAMPLE_RATIO"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Fatalf("Load() error = %v, want it to mention %s", err, key)
+		}
+	}
+	// Nilai yang gagal di-parse jatuh ke default
+	if cfg.Server.ReadTimeout != 30*time.Second || cfg.Database.MaxOpenConns != 25 {
+		t.Fatalf("defaults not applied: ReadTimeout=%s MaxOpenConns=%d", cfg.Server.ReadTimeout, cfg.Database.MaxOpenConns)
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret adalah string rahasia (password, signing key) yang otomatis
+// disamarkan saat dicetak, di-log (slog) atau di-marshal ke JSON.
+// Nilai asli hanya bisa diambil lewat Value().
+type Secret string
+
+// Value mengembalikan nilai asli
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return `"` + s.String() + `"`
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
@@ -1,61 +1,58 @@
 package database
 
 import (
-	"fmt"
-	"log"
-	"os"
+	"context"
+	"log/slog"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
-	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/config"
+	"github.com/wtppaul/course-service/internal/logging"
 )
 
-var DB *gorm.DB
-
-func InitDB() {
-	// 🎈 1. Baca environment variable yang diset di docker-compose.yml
-	host := os.Getenv("DATABASE_HOST")
-	user := os.Getenv("DATABASE_USER")
-	password := os.Getenv("DATABASE_PASSWORD")
-	dbname := os.Getenv("DATABASE_NAME")
-	port := os.Getenv("DATABASE_PORT")
-	sslmode := os.Getenv("DATABASE_SSL_MODE")
-
-	// 🎈 2. Bangun DSN (Data Source Name) dari env vars
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		host, user, password, dbname, port, sslmode)
-
-	// 🎈 3. Cek apakah env var yang penting ada (cek host saja cukup)
-	if host == "" {
-		log.Fatal("❌ Database configuration not set (check DATABASE_HOST etc.)")
-	}
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Connect membuka koneksi ke Postgres & mengatur connection pool (tanpa migrasi)
+func Connect(cfg config.DatabaseConfig) *gorm.DB {
+	// 🎈 1. Buka koneksi. DSN berisi password, jadi hanya host/port/nama
+	// database yang boleh muncul di log.
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
+		TranslateError: true, // Unique violation -> gorm.ErrDuplicatedKey
+	})
 	if err != nil {
-		log.Fatalf("❌ Failed to connect to database: %v. DSN: %s", err, dsn) // Tambahkan DSN ke log error
+		logging.Fatal("failed to connect to database", "error", err, "host", cfg.Host, "port", cfg.Port, "database", cfg.Name)
 	}
 
-	// 🎈 4. Enable uuid-ossp extension (safe if already exists)
-	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`).Error; err != nil {
-		log.Fatalf("❌ Failed to enable uuid-ossp extension: %v", err)
+	// 🎈 2. Atur connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		logging.Fatal("failed to get database handle", "error", err)
 	}
-
-	// 🎈 5. Auto migrate all models
-	fmt.Println("Running migrations...")
-	if err := db.AutoMigrate(
-		&models.Course{},
-		&models.Chapter{},
-		&models.Lesson{},
-		&models.Category{},
-		&models.Tag{},
-		&models.Sale{},
-		&models.Coupon{},
-	); err != nil {
-		log.Fatalf("❌ Migration failed: %v", err)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return db
+}
+
+// InitDB menghubungkan ke database, lalu (jika autoMigrate) menjalankan
+// migrasi yang tertunda. Aman untuk banyak replika sekaligus karena
+// migrator memegang advisory lock.
+func InitDB(cfg config.DatabaseConfig, autoMigrate bool) *gorm.DB {
+	db := Connect(cfg)
+
+	if autoMigrate {
+		slog.Info("running migrations")
+		migrator, err := NewMigrator(db)
+		if err != nil {
+			logging.Fatal("failed to load migrations", "error", err)
+		}
+		if err := migrator.Up(context.Background()); err != nil {
+			logging.Fatal("migration failed", "error", err)
+		}
+		slog.Info("migration done")
 	}
-	fmt.Println("✅ Migration done!")
 
-	DB = db
-	fmt.Println("✅ Database connected & migrated successfully.")
-}
\ No newline at end of file
+	slog.Info("database connected", "host", cfg.Host, "database", cfg.Name)
+	return db
+}
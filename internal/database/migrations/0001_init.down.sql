@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS coupon_categories;
+DROP TABLE IF EXISTS coupon_courses;
+DROP TABLE IF EXISTS course_sales;
+DROP TABLE IF EXISTS course_tags;
+DROP TABLE IF EXISTS course_categories;
+DROP TABLE IF EXISTS coupons;
+DROP TABLE IF EXISTS sales;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS chapters;
+DROP TABLE IF EXISTS courses;
+DROP TABLE IF EXISTS teachers;
@@ -0,0 +1,108 @@
+-- Skema awal (sebelumnya dibuat oleh GORM AutoMigrate).
+-- Memakai IF NOT EXISTS agar aman dijalankan di database yang sudah ada.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS teachers (
+    id       uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    auth_id  text NOT NULL UNIQUE,
+    name     text NOT NULL,
+    bio      text,
+    username text NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS courses (
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title       text NOT NULL,
+    description text,
+    thumbnail   text,
+    price       numeric DEFAULT 0,
+    teacher_id  uuid NOT NULL REFERENCES teachers (id),
+    slug        text NOT NULL UNIQUE,
+    level       varchar(50),
+    status      varchar(50) DEFAULT 'DRAFT',
+    is_free     boolean DEFAULT false,
+    license     varchar(10) DEFAULT 'NT',
+    created_at  timestamptz DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS chapters (
+    id        uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title     text NOT NULL,
+    "order"   bigint NOT NULL,
+    course_id uuid NOT NULL REFERENCES courses (id),
+    slug      text NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS lessons (
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title       text NOT NULL,
+    "order"     bigint NOT NULL,
+    chapter_id  uuid NOT NULL REFERENCES chapters (id),
+    duration    bigint,
+    playback_id text NOT NULL,
+    is_preview  boolean DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id        uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name      text NOT NULL UNIQUE,
+    slug      text NOT NULL UNIQUE,
+    parent_id uuid REFERENCES categories (id)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id   uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name text NOT NULL UNIQUE,
+    slug text NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS sales (
+    id             uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name           text NOT NULL,
+    discount_type  varchar(50) NOT NULL,
+    discount_value numeric NOT NULL,
+    start_date     timestamptz NOT NULL,
+    end_date       timestamptz NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS coupons (
+    id             uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code           text NOT NULL UNIQUE,
+    discount_type  varchar(50) NOT NULL,
+    discount_value numeric NOT NULL,
+    expires_at     timestamptz,
+    max_uses       bigint,
+    current_uses   bigint DEFAULT 0
+);
+
+-- Tabel relasi many-to-many
+CREATE TABLE IF NOT EXISTS course_categories (
+    course_id   uuid NOT NULL REFERENCES courses (id),
+    category_id uuid NOT NULL REFERENCES categories (id),
+    PRIMARY KEY (course_id, category_id)
+);
+
+CREATE TABLE IF NOT EXISTS course_tags (
+    course_id uuid NOT NULL REFERENCES courses (id),
+    tag_id    uuid NOT NULL REFERENCES tags (id),
+    PRIMARY KEY (course_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS course_sales (
+    course_id uuid NOT NULL REFERENCES courses (id),
+    sale_id   uuid NOT NULL REFERENCES sales (id),
+    PRIMARY KEY (course_id, sale_id)
+);
+
+CREATE TABLE IF NOT EXISTS coupon_courses (
+    coupon_id uuid NOT NULL REFERENCES coupons (id),
+    course_id uuid NOT NULL REFERENCES courses (id),
+    PRIMARY KEY (coupon_id, course_id)
+);
+
+CREATE TABLE IF NOT EXISTS coupon_categories (
+    coupon_id   uuid NOT NULL REFERENCES coupons (id),
+    category_id uuid NOT NULL REFERENCES categories (id),
+    PRIMARY KEY (coupon_id, category_id)
+);
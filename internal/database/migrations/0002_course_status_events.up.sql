@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS course_status_events (
+    id            uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    course_id     uuid NOT NULL REFERENCES courses (id),
+    from_status   varchar(50) NOT NULL,
+    to_status     varchar(50) NOT NULL,
+    actor_auth_id text NOT NULL,
+    reason        text,
+    created_at    timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_status_events_course_id ON course_status_events (course_id, created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id          uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    coupon_id   uuid NOT NULL REFERENCES coupons (id),
+    course_id   uuid NOT NULL REFERENCES courses (id),
+    auth_id     text,
+    redeemed_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+    released_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_coupon_id ON coupon_redemptions (coupon_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    aggregate_id    uuid NOT NULL,
+    event_type      varchar(100) NOT NULL,
+    payload         jsonb NOT NULL,
+    attempts        bigint DEFAULT 0,
+    last_error      text,
+    next_attempt_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+    published_at    timestamptz,
+    created_at      timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Relay hanya membaca event yang belum terkirim
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (next_attempt_at) WHERE published_at IS NULL;
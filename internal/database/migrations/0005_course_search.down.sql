@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS trg_categories_search_vector ON categories;
+DROP TRIGGER IF EXISTS trg_tags_search_vector ON tags;
+DROP TRIGGER IF EXISTS trg_course_categories_search_vector ON course_categories;
+DROP TRIGGER IF EXISTS trg_course_tags_search_vector ON course_tags;
+DROP TRIGGER IF EXISTS trg_courses_search_vector ON courses;
+
+DROP FUNCTION IF EXISTS taxonomy_search_vector_trigger();
+DROP FUNCTION IF EXISTS course_links_search_vector_trigger();
+DROP FUNCTION IF EXISTS courses_search_vector_trigger();
+DROP FUNCTION IF EXISTS course_search_vector(uuid, text, text);
+
+DROP INDEX IF EXISTS idx_courses_search_vector;
+ALTER TABLE courses DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,87 @@
+-- Full-text search untuk course.
+-- Bobot: judul (A), nama tag & kategori (B), deskripsi (C).
+-- Memakai konfigurasi 'simple' karena konten campuran Indonesia/Inggris.
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION course_search_vector(p_course_id uuid, p_title text, p_description text)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(p_title, '')), 'A')
+        || setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(t.name, ' ') FROM course_tags ct
+               JOIN tags t ON t.id = ct.tag_id
+               WHERE ct.course_id = p_course_id), '')), 'B')
+        || setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(c.name, ' ') FROM course_categories cc
+               JOIN categories c ON c.id = cc.category_id
+               WHERE cc.course_id = p_course_id), '')), 'B')
+        || setweight(to_tsvector('simple', coalesce(p_description, '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+-- 1. Judul/deskripsi berubah
+CREATE OR REPLACE FUNCTION courses_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := course_search_vector(NEW.id, NEW.title, NEW.description);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_courses_search_vector ON courses;
+CREATE TRIGGER trg_courses_search_vector
+    BEFORE INSERT OR UPDATE OF title, description ON courses
+    FOR EACH ROW EXECUTE FUNCTION courses_search_vector_trigger();
+
+-- 2. Relasi tag/kategori sebuah course berubah
+CREATE OR REPLACE FUNCTION course_links_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE courses SET search_vector = course_search_vector(id, title, description)
+        WHERE id = NEW.course_id;
+    END IF;
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        UPDATE courses SET search_vector = course_search_vector(id, title, description)
+        WHERE id = OLD.course_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_course_tags_search_vector ON course_tags;
+CREATE TRIGGER trg_course_tags_search_vector
+    AFTER INSERT OR UPDATE OR DELETE ON course_tags
+    FOR EACH ROW EXECUTE FUNCTION course_links_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS trg_course_categories_search_vector ON course_categories;
+CREATE TRIGGER trg_course_categories_search_vector
+    AFTER INSERT OR UPDATE OR DELETE ON course_categories
+    FOR EACH ROW EXECUTE FUNCTION course_links_search_vector_trigger();
+
+-- 3. Nama tag/kategori berubah
+CREATE OR REPLACE FUNCTION taxonomy_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'tags' THEN
+        UPDATE courses SET search_vector = course_search_vector(id, title, description)
+        WHERE id IN (SELECT course_id FROM course_tags WHERE tag_id = NEW.id);
+    ELSE
+        UPDATE courses SET search_vector = course_search_vector(id, title, description)
+        WHERE id IN (SELECT course_id FROM course_categories WHERE category_id = NEW.id);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tags_search_vector ON tags;
+CREATE TRIGGER trg_tags_search_vector
+    AFTER UPDATE OF name ON tags
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION taxonomy_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS trg_categories_search_vector ON categories;
+CREATE TRIGGER trg_categories_search_vector
+    AFTER UPDATE OF name ON categories
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION taxonomy_search_vector_trigger();
+
+-- 4. Backfill & index
+UPDATE courses SET search_vector = course_search_vector(id, title, description);
+
+CREATE INDEX IF NOT EXISTS idx_courses_search_vector ON courses USING GIN (search_vector);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS students (
+    id       uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    auth_id  text NOT NULL UNIQUE,
+    name     text NOT NULL,
+    username text NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    id            uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    student_id    uuid NOT NULL REFERENCES students (id),
+    course_id     uuid NOT NULL REFERENCES courses (id),
+    source        varchar(20) NOT NULL CHECK (source IN ('PURCHASE', 'FREE', 'COUPON', 'ADMIN_GRANT')),
+    reference     text,
+    granted_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    timestamptz,
+    revoked_at    timestamptz,
+    revoke_reason text
+);
+
+-- Maksimal satu enrollment aktif (belum dicabut) per student per course
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_active ON enrollments (student_id, course_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_enrollments_course_id ON enrollments (course_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS lesson_progress (
+    id               uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    student_id       uuid NOT NULL REFERENCES students (id),
+    lesson_id        uuid NOT NULL REFERENCES lessons (id) ON DELETE CASCADE,
+    course_id        uuid NOT NULL REFERENCES courses (id),
+    position_seconds bigint NOT NULL DEFAULT 0,
+    percent_watched  numeric(5,2) NOT NULL DEFAULT 0,
+    completed_at     timestamptz,
+    updated_at       timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Satu baris per student per lesson (target ON CONFLICT untuk upsert)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lesson_progress_student_lesson ON lesson_progress (student_id, lesson_id);
+-- Ringkasan progress per course
+CREATE INDEX IF NOT EXISTS idx_lesson_progress_student_course ON lesson_progress (student_id, course_id);
@@ -0,0 +1,3 @@
+ALTER TABLE lessons  DROP COLUMN IF EXISTS version;
+ALTER TABLE chapters DROP COLUMN IF EXISTS version;
+ALTER TABLE courses  DROP COLUMN IF EXISTS version;
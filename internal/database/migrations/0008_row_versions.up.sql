@@ -0,0 +1,4 @@
+-- Versi baris untuk optimistic concurrency (ETag / If-Match)
+ALTER TABLE courses  ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+ALTER TABLE lessons  ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/problem"
+	"github.com/wtppaul/course-service/internal/service"
+)
+
+// === HANDLER KATEGORI (Admin, via BFF) ===
+
+// CreateCategory (POST /internal/categories)
+func (h *CourseHandler) CreateCategory(c *gin.Context) {
+	// 1. Bind JSON body
+	var input struct {
+		Name     string     `json:"name" binding:"required"`
+		ParentID *uuid.UUID `json:"parentId"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	// 2. Cek parent + slug unik + simpan (satu transaksi)
+	category, err := h.service.CreateCategory(c.Request.Context(), service.CreateCategoryInput{
+		Name:     input.Name,
+		ParentID: input.ParentID,
+	})
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory (PATCH /internal/categories/:id)
+// Slug sengaja tidak diubah agar URL katalog tetap stabil
+func (h *CourseHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	updatedCategory, err := h.service.UpdateCategory(c.Request.Context(), categoryID, input.Name)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedCategory)
+}
+
+// MoveCategory (POST /internal/categories/:id/move)
+// Body: { "parentId": "uuid" } atau { "parentId": null } untuk jadi root
+func (h *CourseHandler) MoveCategory(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	var input struct {
+		ParentID *uuid.UUID `json:"parentId"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	if err := h.service.MoveCategory(c.Request.Context(), categoryID, input.ParentID); err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category moved successfully"})
+}
+
+// DeleteCategory (DELETE /internal/categories/:id)
+func (h *CourseHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	if err := h.service.DeleteCategory(c.Request.Context(), categoryID); err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+// GetCategoryTree (GET /internal/categories/tree)
+func (h *CourseHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.service.CategoryTree(c.Request.Context())
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// UpdateCourseCategories (PATCH /internal/courses/:id/categories)
+func (h *CourseHandler) UpdateCourseCategories(c *gin.Context) {
+	// 1. Ambil CourseID dari URL
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	// 2. Bind JSON body (array of category IDs)
+	var input struct {
+		CategoryIDs []uuid.UUID `json:"categoryIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	// 3. Simpan (jika input.CategoryIDs kosong, semua kategori dilepas)
+	if err := h.service.UpdateCourseCategories(c.Request.Context(), courseID, input.CategoryIDs); err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Categories updated successfully"})
+}
@@ -1,18 +1,18 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"strconv" 
-	"fmt"
+	"strings"
 
-	"gorm.io/gorm"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/wtppaul/course-service/internal/middleware"
 	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/problem"
 	"github.com/wtppaul/course-service/internal/repository"
-	"github.com/wtppaul/course-service/internal/utils" 
+	"github.com/wtppaul/course-service/internal/service"
 )
 
 const (
@@ -20,12 +20,14 @@ const (
 	DefaultLimit = 10
 )
 
+// CourseHandler menerjemahkan HTTP <-> service. Semua bacaan & perubahan
+// lewat service.CourseService; handler tidak memanggil repository langsung.
 type CourseHandler struct {
-	repo repository.ICourseRepository
+	service *service.CourseService
 }
 
-func NewCourseHandler(repo repository.ICourseRepository) *CourseHandler {
-	return &CourseHandler{repo: repo}
+func NewCourseHandler(courseService *service.CourseService) *CourseHandler {
+	return &CourseHandler{service: courseService}
 }
 
 // === HANDLER PUBLIK (via BFF) ===
@@ -35,25 +37,22 @@ func NewCourseHandler(repo repository.ICourseRepository) *CourseHandler {
 func (h *CourseHandler) GetCourseBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
-	course, err := h.repo.GetCourseBySlug(c.Request.Context(), slug)
+	course, err := h.service.CourseBySlug(c.Request.Context(), slug)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		problem.Respond(c, err)
 		return
 	}
 
+	setETag(c, course.Version)
 	c.JSON(http.StatusOK, course)
 }
 
 // GetPublishedCourses (GET /internal/courses/public)
 func (h *CourseHandler) GetPublishedCourses(c *gin.Context) {
 	// (Di sini Anda bisa menambahkan parsing query param 'page' dan 'limit')
-	courses, err := h.repo.GetPublishedCourses(c.Request.Context(), 1, 20)
+	courses, err := h.service.PublishedCourses(c.Request.Context(), 1, 20)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -64,53 +63,68 @@ func (h *CourseHandler) GetPublishedCourses(c *gin.Context) {
 
 // CreateCourse (POST /internal/courses)
 func (h *CourseHandler) CreateCourse(c *gin.Context) {
-	// 1. Ambil "Paspor" (AuthID String) dari context
-	authIDStr, exists := c.Get("authenticatedUserID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing user context"})
-		return
-	}
-
-	// 2. ✅ PERBAIKAN: Tukar "Paspor" (AuthID) dengan "Profil" (Teacher)
-	// Ini akan mencari teacher, atau membuat profil baru jika tidak ada.
-	teacher, err := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve teacher profile"})
+	// 1. Ambil "Paspor" (AuthID) dari context
+	authID := c.GetString("authenticatedUserID")
+	if authID == "" {
+		problem.Respond(c, middleware.ErrUnauthenticated)
 		return
 	}
 
-	// 3. Bind JSON body
+	// 2. Bind JSON body
 	var input struct {
 		Title string `json:"title" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
-	
-	// 4. Buat slug yang unik
-	slug, err := utils.GenerateUniqueSlug(c.Request.Context(), input.Title, h.repo)
+
+	// 3. Service: resolve teacher + slug + simpan (satu transaksi)
+	course, err := h.service.CreateCourse(c.Request.Context(), service.CreateCourseInput{
+		AuthID: authID,
+		Title:  input.Title,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate slug"})
+		problem.Respond(c, err)
 		return
 	}
 
-	// 5. Buat objek Course
-	course := &models.Course{
-		Title:     input.Title,
-		Slug:      slug,
-		TeacherID: teacher.ID, 
-		Status:    models.StatusDraft,
-		License:   models.LicenseNT,
+	c.JSON(http.StatusCreated, course)
+}
+
+// UpdateCourse (PATCH /internal/courses/:id)
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	// 1. Ambil CourseID dari URL
+	courseIDStr := c.Param("id")
+	courseID, err := uuid.Parse(courseIDStr)
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	// 2. Bind JSON body (hanya field yang boleh di-update)
+	var input repository.UpdateCourseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
 	}
 
-	// 6. Simpan ke DB
-	if err := h.repo.CreateCourse(c.Request.Context(), course); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create course"})
+	// 2b. Versi yang sedang diedit client (wajib)
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
+	input.Version = version
 
-	c.JSON(http.StatusCreated, course)
+	// 3. Simpan (ditolak jika versi sudah usang)
+	updatedCourse, err := h.service.UpdateCourse(c.Request.Context(), courseID, input)
+	if err != nil {
+		respondUpdateError(c, err)
+		return
+	}
+
+	setETag(c, updatedCourse.Version)
+	c.JSON(http.StatusOK, updatedCourse)
 }
 
 // UpdateCourseStatus (PATCH /internal/courses/:id/status)
@@ -118,33 +132,79 @@ func (h *CourseHandler) UpdateCourseStatus(c *gin.Context) {
 	courseIDStr := c.Param("id")
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 
-	// (Di sini, BFF seharusnya sudah memverifikasi bahwa
-	// X-Authenticated-User-ID adalah pemilik kursus ini
-	// atau seorang Admin/Curator)
-
 	var input struct {
 		Status models.CourseStatus `json:"status" binding:"required"`
+		Reason string              `json:"reason"` // Catatan reviewer (opsional)
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
-	
-	// (Validasi input status jika perlu)
-	
-	err = h.repo.UpdateCourseStatus(c.Request.Context(), courseID, input.Status)
+
+	// Route hanya bisa diakses pemilik, curator atau admin (policy di routes);
+	// transisi mana yang boleh untuk role tersebut (dan kepemilikan course)
+	// diatur oleh service
+	principal := middleware.PrincipalFrom(c)
+	isOwner, err := h.service.IsCourseOwner(c.Request.Context(), courseID, principal.AuthID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
+		problem.Respond(c, err)
+		return
+	}
+	err = h.service.ChangeStatus(c.Request.Context(), service.ChangeStatusInput{
+		CourseID:    courseID,
+		Status:      input.Status,
+		Reason:      input.Reason,
+		ActorAuthID: principal.AuthID,
+		Roles:       principal.Roles,
+		IsOwner:     isOwner,
+	})
+	if err != nil {
+		problem.Respond(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Status updated successfully"})
 }
 
+// GetCourseReadiness (GET /internal/courses/:id/readiness)
+// Checklist per aturan sebelum course boleh diajukan ke review
+func (h *CourseHandler) GetCourseReadiness(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	report, err := h.service.Readiness(c.Request.Context(), courseID)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCourseStatusHistory (GET /internal/courses/:id/status-history)
+func (h *CourseHandler) GetCourseStatusHistory(c *gin.Context) {
+	courseIDStr := c.Param("id")
+	courseID, err := uuid.Parse(courseIDStr)
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	events, err := h.service.StatusHistory(c.Request.Context(), courseID)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
 
 // ✅ 
 // GetCoursesByTeacherID (GET /internal/teachers/:teacherId/courses)
@@ -153,51 +213,145 @@ func (h *CourseHandler) GetCoursesByTeacherID(c *gin.Context) {
 	teacherIDStr := c.Param("teacherId")
 	teacherID, err := uuid.Parse(teacherIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher ID format"})
+		problem.Respond(c, problem.InvalidID("teacherId"))
 		return
 	}
 
-	// 2. ⛔️ HAPUS LOGIKA KEAMANAN DARI SINI
-	//    (Middleware internal kita sudah memvalidasi X-Internal-Secret)
-	//    Logika "apakah user ini pemilik" adalah tugas BFF, BUKAN service ini.
-	/*
-	authIDStr, _ := c.Get("authenticatedUserID")
-	teacher, _ := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authIDStr.(string))
-	if teacher.ID != teacherID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: You can only view your own courses"})
-		return
-	}
-	*/
+	// (Hanya teacher yang bersangkutan, curator atau admin: policy di routes)
 
-	// 3. Panggil Repository (Logika "Bodoh")
-	//    "Ambilkan saya semua kursus (termasuk draft) untuk teacher ini"
-	courses, err := h.repo.GetCoursesByTeacherID(c.Request.Context(), teacherID)
+	// 2. Ambil semua kursus (termasuk draft) untuk teacher ini
+	courses, err := h.service.TeacherCourses(c.Request.Context(), teacherID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get courses"})
+		problem.Respond(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, courses)
 }
 
-// --- TODO: Handler untuk Pricing ---
+// --- Handler untuk Pricing ---
 // (Handler ini akan dipanggil oleh Payment-service)
 
 // GetPricingDetails (GET /internal/courses/:id/pricing)
+// Respons ini adalah quote resmi: Payment-service memakai 'finalPrice' apa adanya.
 func (h *CourseHandler) GetPricingDetails(c *gin.Context) {
-	// 1. Ambil harga dasar
-	// 2. Ambil sales aktif (via repo)
-	// 3. Kembalikan semua info harga
+	courseIDStr := c.Param("id")
+	courseID, err := uuid.Parse(courseIDStr)
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	// Harga dasar + sale terbaik (lihat service.Quote)
+	quote, err := h.service.Quote(c.Request.Context(), courseID)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// couponInput adalah body untuk endpoint validasi & redeem kupon
+type couponInput struct {
+	Code     string    `json:"code" binding:"required"`
+	CourseID uuid.UUID `json:"courseId" binding:"required"`
 }
 
 // ValidateCoupon (POST /internal/coupons/validate)
+// Hanya mengecek kupon (tidak memakai kuota)
 func (h *CourseHandler) ValidateCoupon(c *gin.Context) {
-	// 1. Ambil kode kupon
-	// 2. Panggil repo.FindValidCoupon
+	// 1. Ambil kode kupon & course
+	var input couponInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	// 2. Cek kupon (termasuk scope course/kategori), diterapkan setelah sale terbaik
+	quote, err := h.service.ValidateCoupon(c.Request.Context(), service.CouponInput{
+		Code:     input.Code,
+		CourseID: input.CourseID,
+	})
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
 	// 3. Kembalikan detail kupon jika valid
+	c.JSON(http.StatusOK, gin.H{
+		"valid":      true,
+		"coupon":     quote.Coupon,
+		"price":      quote.Price,
+		"finalPrice": quote.FinalPrice,
+	})
+}
+
+// RedeemCoupon (POST /internal/coupons/redeem)
+// Memakai satu kuota kupon secara atomik (dipanggil Payment-service saat checkout)
+func (h *CourseHandler) RedeemCoupon(c *gin.Context) {
+	var input couponInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	quote, err := h.service.RedeemCoupon(c.Request.Context(), service.CouponInput{
+		Code:     input.Code,
+		CourseID: input.CourseID,
+		AuthID:   c.GetString("authenticatedUserID"),
+	})
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"redemptionId": quote.RedemptionID,
+		"coupon":       quote.Coupon,
+		"price":        quote.Price,
+		"finalPrice":   quote.FinalPrice,
+	})
+}
+
+// ReleaseCoupon (POST /internal/coupons/release)
+// Mengembalikan kuota kupon jika pembayaran gagal
+func (h *CourseHandler) ReleaseCoupon(c *gin.Context) {
+	var input struct {
+		RedemptionID uuid.UUID `json:"redemptionId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	if err := h.service.ReleaseCoupon(c.Request.Context(), input.RedemptionID); err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Coupon released successfully"})
 }
 
 // ✅
+// listableStatuses membatasi filter status listing. Course yang belum/tidak
+// dipublikasikan hanya boleh didaftar curator/admin; teacher melihat course
+// miliknya lewat /teachers/:teacherId/courses.
+func listableStatuses(p middleware.Principal, requested []string) ([]string, error) {
+	if p.HasRole(models.RoleCurator, models.RoleAdmin) {
+		return requested, nil // Kosong = semua status
+	}
+	if len(requested) == 0 {
+		return []string{string(models.StatusPublished)}, nil
+	}
+	for _, status := range requested {
+		if status != string(models.StatusPublished) {
+			return nil, middleware.ErrForbidden
+		}
+	}
+	return requested, nil
+}
+
 // GetCourses (GET /internal/courses)
 // Menerima query params dinamis dari BFF
 func (h *CourseHandler) GetCourses(c *gin.Context) {
@@ -214,7 +368,11 @@ func (h *CourseHandler) GetCourses(c *gin.Context) {
 	}
 
 	// 2. Parsing Filter
-	statusFilter := c.QueryArray("status")
+	statusFilter, err := listableStatuses(middleware.PrincipalFrom(c), c.QueryArray("status"))
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
 	levelFilter := c.QueryArray("level")
 	
 	// ✅ (BARU) Ambil filter kategori dan tag
@@ -226,14 +384,36 @@ func (h *CourseHandler) GetCourses(c *gin.Context) {
 		Level:         levelFilter,
 		CategorySlugs: categoryFilter, // ✅ (BARU)
 		TagSlugs:      tagFilter,      // ✅ (BARU)
+		Query:         strings.TrimSpace(c.Query("q")),
 		Page:          page,
 		Limit:         limit,
 	}
 
-	// 3. Panggil Repository
-	courses, total, err := h.repo.GetCourses(ctx, filters)
+	// 3a. Pencarian full-text: urut relevansi + snippet + facet
+	if filters.Query != "" {
+		result, err := h.service.SearchCourses(ctx, filters)
+		if err != nil {
+			problem.Respond(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":   result.Hits,
+			"facets": result.Facets,
+			"pagination": gin.H{
+				"total":      result.Total,
+				"page":       page,
+				"limit":      limit,
+				"totalPages": (result.Total + int64(limit) - 1) / int64(limit),
+			},
+		})
+		return
+	}
+
+	// 3b. Listing biasa
+	courses, total, err := h.service.ListCourses(ctx, filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -257,20 +437,17 @@ func (h *CourseHandler) GetCourseById(c *gin.Context) {
 	courseIDStr := c.Param("id")
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 
-	course, err := h.repo.GetCourseDetails(ctx, courseID)
+	course, err := h.service.CourseDetails(ctx, courseID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error", "details": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
+	setETag(c, course.Version)
 	c.JSON(http.StatusOK, course)
 }
 
@@ -283,11 +460,11 @@ func (h *CourseHandler) UpdateCourseTags(c *gin.Context) {
 	courseIDStr := c.Param("id")
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 	
-	// (BFF sudah memvalidasi kepemilikan)
+	// (Kepemilikan sudah dicek oleh policy di routes)
 	
 	// 2. Bind JSON body (array of tag IDs)
 	var input struct {
@@ -295,14 +472,13 @@ func (h *CourseHandler) UpdateCourseTags(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 	
-	// 3. Panggil repository
-	//    (Jika input.TagIDs kosong, GORM akan menghapus semua tag)
-	if err := h.repo.UpdateCourseTags(ctx, courseID, input.TagIDs); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tags"})
+	// 3. Simpan (jika input.TagIDs kosong, semua tag dilepas)
+	if err := h.service.UpdateCourseTags(ctx, courseID, input.TagIDs); err != nil {
+		problem.Respond(c, err)
 		return
 	}
 
@@ -310,52 +486,34 @@ func (h *CourseHandler) UpdateCourseTags(c *gin.Context) {
 }
 
 // ✅
-// CreateChapter (POST /internal/courses/:courseId/chapters)
+// CreateChapter (POST /internal/courses/:id/chapters)
 func (h *CourseHandler) CreateChapter(c *gin.Context) {
-	ctx := c.Request.Context()
-	
 	// 1. Ambil CourseID dari URL
-	courseIDStr := c.Param("courseId")
+	courseIDStr := c.Param("id") // (gin: wildcard harus sama dengan /courses/:id)
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 
-	// 2. Ambil AuthID (Teacher) dari header
-	// (Middleware sudah memvalidasi X-Internal-Secret)
-	// BFF "pintar" HARUS sudah memvalidasi kepemilikan.
-	// Service "bodoh" ini hanya mengeksekusi.
-	authID := c.GetString("authenticatedUserID")
-	if authID == "" {
-		// Ini seharusnya tidak pernah terjadi jika middleware internal berjalan
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Missing auth context"})
-		return
-	}
-	
-	// 3. Bind JSON body (data Chapter)
+	// 2. Bind JSON body (data Chapter)
 	var input struct {
 		Title string `json:"title" binding:"required"`
 		Order int    `json:"order"` // Order bisa 0 atau di-set
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 
-	// 4. Buat objek Chapter
-	chapter := &models.Chapter{
+	// 3. Simpan (slug chapter dibuat oleh service)
+	chapter, err := h.service.CreateChapter(c.Request.Context(), service.CreateChapterInput{
 		CourseID: courseID,
 		Title:    input.Title,
 		Order:    input.Order,
-		// Buat slug unik untuk chapter (misal: "chapter-judul-chapter-acak")
-		// Kita tidak perlu cek DB untuk ini, cukup buat unik
-		Slug:     fmt.Sprintf("chapter-%s-%s", utils.CreateSlug(input.Title), utils.RandomString(6)),
-	}
-
-	// 5. Simpan ke DB
-	if err := h.repo.CreateChapter(ctx, chapter); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chapter"})
+	})
+	if err != nil {
+		problem.Respond(c, err)
 		return
 	}
 
@@ -363,183 +521,114 @@ func (h *CourseHandler) CreateChapter(c *gin.Context) {
 }
 
 // ✅
-// UpdateChapter (PATCH /internal/courses/:courseId/chapters/:chapterId)
+// UpdateChapter (PATCH /internal/courses/:id/chapters/:chapterId)
 func (h *CourseHandler) UpdateChapter(c *gin.Context) {
 	// 1. Ambil ID dari URL
-	courseIDStr := c.Param("courseId")
+	courseIDStr := c.Param("id") // (gin: wildcard harus sama dengan /courses/:id)
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 	chapterIDStr := c.Param("chapterId")
 	chapterID, err := uuid.Parse(chapterIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter ID format"})
+		problem.Respond(c, problem.InvalidID("chapterId"))
 		return
 	}
 
-	// 2. Ambil "Paspor" (AuthID) dari context
-	authID, exists := c.Get("authenticatedUserID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing user context"})
-		return
-	}
-
-	// 3. Bind JSON body (hanya field yang boleh di-update)
+	// 2. Bind JSON body (hanya field yang boleh di-update)
 	var input struct {
 		Title string `json:"title"`
 		Order *int   `json:"order"` // Pointer agar bisa bedakan 0 vs. 'tidak dikirim'
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 
-	// 4. Verifikasi Kepemilikan (Defense in Depth)
-	teacher, err := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authID.(string))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify teacher"})
-		return
-	}
-	course, err := h.repo.GetCourseDetails(c.Request.Context(), courseID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		return
-	}
-	if course.TeacherID != teacher.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: You do not own this course"})
-		return
-	}
-
-	// 5. Ambil data Chapter yang ada
-	chapter, err := h.repo.GetChapterByID(c.Request.Context(), chapterID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+	// 2b. Versi yang sedang diedit client (wajib)
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	// 6. Terapkan perubahan
-	if input.Title != "" {
-		chapter.Title = input.Title
-		// (Opsional: Buat slug baru jika title berubah?
-		//  Untuk Chapter, ini mungkin OK, tapi kita biarkan dulu)
-	}
-	if input.Order != nil {
-		chapter.Order = *input.Order
-	}
-
-	// 7. Simpan ke DB
-	updatedChapter, err := h.repo.UpdateChapter(c.Request.Context(), chapter)
+	// 3. Simpan (chapter harus milik course di URL; kepemilikan course
+	//    sudah dicek oleh policy di routes)
+	updatedChapter, err := h.service.UpdateChapter(c.Request.Context(), service.UpdateChapterInput{
+		CourseID:  courseID,
+		ChapterID: chapterID,
+		Title:     input.Title,
+		Order:     input.Order,
+		Version:   version,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update chapter"})
+		respondUpdateError(c, err)
 		return
 	}
 
+	setETag(c, updatedChapter.Version)
 	c.JSON(http.StatusOK, updatedChapter)
 }
 
 // ✅
-// ReorderChapters (POST /internal/courses/:courseId/chapters/reorder)
+// ReorderChapters (POST /internal/courses/:id/chapters/reorder)
 func (h *CourseHandler) ReorderChapters(c *gin.Context) {
 	// 1. Ambil Course ID dari URL
-	courseIDStr := c.Param("courseId")
+	courseIDStr := c.Param("id") // (gin: wildcard harus sama dengan /courses/:id)
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 
-	// 2. Ambil "Paspor" (AuthID) dari context
-	authID, exists := c.Get("authenticatedUserID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing user context"})
-		return
-	}
-
-	// 3. Bind JSON body (Array of updates)
+	// 2. Bind JSON body (Array of updates)
 	var input []repository.ChapterReorderInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: expected array of {id, order}"})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 
-	// 4. Verifikasi Kepemilikan (Defense in Depth)
-	teacher, err := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authID.(string))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify teacher"})
-		return
-	}
-	course, err := h.repo.GetCourseDetails(c.Request.Context(), courseID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		return
-	}
-	if course.TeacherID != teacher.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: You do not own this course"})
+	// 2b. Versi course yang dilihat editor (wajib)
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	// 5. Panggil Repository (yang akan menjalankan Transaksi)
-	err = h.repo.ReorderChapters(c.Request.Context(), courseID, input)
+	// 3. Simpan urutan baru (satu transaksi)
+	newVersion, err := h.service.ReorderChapters(c.Request.Context(), courseID, version, input)
 	if err != nil {
-		// Jika transaksi gagal (misal, salah satu chapterId tidak valid)
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to reorder chapters: %v", err)})
+		// Versi usang -> 412 + course terkini; chapterId tidak valid -> CHAPTER_NOT_IN_COURSE
+		respondUpdateError(c, err)
 		return
 	}
 
+	setETag(c, newVersion)
 	c.JSON(http.StatusOK, gin.H{"message": "Chapters reordered successfully"})
 }
 
 
 // ✅ 
-// DeleteChapter (DELETE /internal/courses/:courseId/chapters/:chapterId)
+// DeleteChapter (DELETE /internal/courses/:id/chapters/:chapterId)
 func (h *CourseHandler) DeleteChapter(c *gin.Context) {
 	// 1. Ambil ID dari URL
-	courseIDStr := c.Param("courseId")
+	courseIDStr := c.Param("id") // (gin: wildcard harus sama dengan /courses/:id)
 	courseID, err := uuid.Parse(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID format"})
+		problem.Respond(c, problem.InvalidID("id"))
 		return
 	}
 	chapterIDStr := c.Param("chapterId")
 	chapterID, err := uuid.Parse(chapterIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter ID format"})
-		return
-	}
-
-	// 2. Ambil "Paspor" (AuthID) dari context
-	authID, exists := c.Get("authenticatedUserID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing user context"})
+		problem.Respond(c, problem.InvalidID("chapterId"))
 		return
 	}
 
-	// 3. Verifikasi Kepemilikan (Defense in Depth)
-	//    (Repository akan memverifikasi ini lagi di dalam transaksi,
-	//     tapi kita cek di sini dulu untuk 'fail-fast')
-	teacher, err := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authID.(string))
+	// 2. Hapus lesson DAN chapter (satu transaksi)
+	err = h.service.DeleteChapter(c.Request.Context(), courseID, chapterID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify teacher"})
-		return
-	}
-	course, err := h.repo.GetCourseDetails(c.Request.Context(), courseID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		return
-	}
-	if course.TeacherID != teacher.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: You do not own this course"})
-		return
-	}
-
-	// 4. Panggil Repository (yang akan menjalankan Transaksi)
-	//    Repository akan menghapus lesson DAN chapter
-	err = h.repo.DeleteChapter(c.Request.Context(), courseID, chapterID)
-	if err != nil {
-		// Jika transaksi gagal (misal, chapter tidak ditemukan)
-		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Failed to delete chapter: %v", err)})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -554,147 +643,221 @@ func (h *CourseHandler) CreateLesson(c *gin.Context) {
 	chapterIDStr := c.Param("chapterId")
 	chapterID, err := uuid.Parse(chapterIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chapter ID format"})
-		return
-	}
-
-	// 2. Ambil "Paspor" (AuthID) dari context
-	authID, exists := c.Get("authenticatedUserID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing user context"})
+		problem.Respond(c, problem.InvalidID("chapterId"))
 		return
 	}
 
-	// 3. Bind JSON body
+	// 2. Bind JSON body
 	var input struct {
 		Title      string `json:"title" binding:"required"`
 		Order      int    `json:"order"`
 		PlaybackID string `json:"playbackId"` // ID Video (dari Upload-service)
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 
-	// 4. Verifikasi Kepemilikan (Defense in Depth)
-	//    Kita harus memastikan chapter ini milik teacher yang benar
-	teacher, err := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authID.(string))
+	// 3. Simpan
+	lesson, err := h.service.CreateLesson(c.Request.Context(), service.CreateLessonInput{
+		ChapterID:  chapterID,
+		Title:      input.Title,
+		Order:      input.Order,
+		PlaybackID: input.PlaybackID,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify teacher"})
+		problem.Respond(c, err)
 		return
 	}
 
-	chapter, err := h.repo.GetChapterByID(c.Request.Context(), chapterID)
+	c.JSON(http.StatusCreated, lesson)
+}
+
+
+// ✅ --- HANDLER BARU UNTUK UPDATE LESSON ---
+// UpdateLesson (PATCH /internal/lessons/:lessonId)
+func (h *CourseHandler) UpdateLesson(c *gin.Context) {
+	// 1. Ambil Lesson ID dari URL
+	lessonIDStr := c.Param("lessonId")
+	lessonID, err := uuid.Parse(lessonIDStr)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+		problem.Respond(c, problem.InvalidID("lessonId"))
 		return
 	}
 
-	course, err := h.repo.GetCourseDetails(c.Request.Context(), chapter.CourseID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course (owner of chapter) not found"})
+	// 2. Bind JSON body (field yang boleh di-update)
+	var input struct {
+		Title      *string `json:"title"`
+		Order      *int    `json:"order"`
+		PlaybackID *string `json:"playbackId"`
+		IsPreview  *bool   `json:"isPreview"`
+		// 'duration' akan di-update oleh service lain (upload-pipeline)
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 
-	if course.TeacherID != teacher.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: You do not own this chapter's course"})
+	// 2b. Versi yang sedang diedit client (wajib)
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
-	
-	// 5. Buat objek Lesson
-	lesson := &models.Lesson{
+
+	// 3. Simpan (hanya field yang dikirim)
+	updatedLesson, err := h.service.UpdateLesson(c.Request.Context(), service.UpdateLessonInput{
+		LessonID:   lessonID,
 		Title:      input.Title,
 		Order:      input.Order,
-		ChapterID:  chapterID,
-		PlaybackID: input.PlaybackID, // 💡 (Akan diisi nanti oleh upload-service)
-	}
-
-	// 6. Simpan ke DB
-	if err := h.repo.CreateLesson(c.Request.Context(), lesson); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lesson"})
+		PlaybackID: input.PlaybackID,
+		IsPreview:  input.IsPreview,
+		Version:    version,
+	})
+	if err != nil {
+		respondUpdateError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, lesson)
+	setETag(c, updatedLesson.Version)
+	c.JSON(http.StatusOK, updatedLesson)
 }
 
 
-// ✅ --- HANDLER BARU UNTUK UPDATE LESSON ---
-// UpdateLesson (PATCH /internal/lessons/:lessonId)
-func (h *CourseHandler) UpdateLesson(c *gin.Context) {
+// DeleteLesson (DELETE /internal/lessons/:lessonId)
+func (h *CourseHandler) DeleteLesson(c *gin.Context) {
 	// 1. Ambil Lesson ID dari URL
 	lessonIDStr := c.Param("lessonId")
 	lessonID, err := uuid.Parse(lessonIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID format"})
+		problem.Respond(c, problem.InvalidID("lessonId"))
 		return
 	}
 
-	// 2. Ambil "Paspor" (AuthID) dari context
-	authID, exists := c.Get("authenticatedUserID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing user context"})
+	// 2. Hapus + rapikan urutan dalam satu transaksi
+	if err := h.service.DeleteLesson(c.Request.Context(), lessonID); err != nil {
+		problem.Respond(c, err)
 		return
 	}
 
-	// 3. Bind JSON body (field yang boleh di-update)
-	var input struct {
-		Title      *string `json:"title"`
-		Order      *int    `json:"order"`
-		PlaybackID *string `json:"playbackId"`
-		IsPreview  *bool   `json:"isPreview"`
-		// 'duration' akan di-update oleh service lain (upload-pipeline)
+	c.JSON(http.StatusOK, gin.H{"message": "Lesson deleted successfully"})
+}
+
+
+// ReorderLessons (POST /internal/chapters/:chapterId/lessons/reorder)
+func (h *CourseHandler) ReorderLessons(c *gin.Context) {
+	// 1. Ambil Chapter ID dari URL
+	chapterIDStr := c.Param("chapterId")
+	chapterID, err := uuid.Parse(chapterIDStr)
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("chapterId"))
+		return
 	}
+
+	// 2. Bind JSON body (Array of updates)
+	var input []repository.LessonReorderInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
 
-	// 4. Ambil data Lesson yang ada (termasuk courseId)
-	lesson, err := h.repo.GetLessonByID(c.Request.Context(), lessonID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+	// 2b. Versi course yang dilihat editor (wajib)
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	// 5. Verifikasi Kepemilikan (Defense in Depth)
-	teacher, err := h.repo.FindOrCreateTeacherByAuthID(c.Request.Context(), authID.(string))
+	// 3. Simpan urutan baru (satu transaksi)
+	newVersion, err := h.service.ReorderLessons(c.Request.Context(), chapterID, version, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify teacher"})
+		// Versi usang -> 412 + course terkini; lessonId tidak valid -> LESSON_NOT_IN_CHAPTER
+		respondUpdateError(c, err)
 		return
 	}
-	
-	// (lesson.CourseID didapat dari 'Join' di GetLessonByID)
-	course, err := h.repo.GetCourseDetails(c.Request.Context(), lesson.CourseID) 
+
+	setETag(c, newVersion)
+	c.JSON(http.StatusOK, gin.H{"message": "Lessons reordered successfully"})
+}
+
+
+// MoveLesson (POST /internal/lessons/:lessonId/move)
+// Memindahkan lesson ke chapter lain (drag & drop), kedua chapter dinomori ulang
+func (h *CourseHandler) MoveLesson(c *gin.Context) {
+	// 1. Ambil Lesson ID dari URL
+	lessonIDStr := c.Param("lessonId")
+	lessonID, err := uuid.Parse(lessonIDStr)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course (owner of lesson) not found"})
+		problem.Respond(c, problem.InvalidID("lessonId"))
+		return
+	}
+
+	// 2. Bind JSON body
+	var input struct {
+		ChapterID uuid.UUID `json:"chapterId" binding:"required"`
+		Order     int       `json:"order"` // Posisi baru (mulai dari 1), 0 = paling akhir
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
 		return
 	}
-	if course.TeacherID != teacher.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: You do not own this lesson's course"})
+
+	// 2b. Versi course yang dilihat editor (wajib)
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	// 3. Pindah + renumber (chapter tujuan harus di course yang sama)
+	moved, newVersion, err := h.service.MoveLesson(c.Request.Context(), lessonID, input.ChapterID, input.Order, version)
+	if err != nil {
+		respondUpdateError(c, err)
 		return
 	}
 
-	// 6. Terapkan perubahan (hanya jika nilainya dikirim)
-	if input.Title != nil {
-		lesson.Title = *input.Title
+	setETag(c, newVersion) // Versi course, bukan versi lesson
+	c.JSON(http.StatusOK, moved)
+}
+
+
+// SaveCurriculum (PUT /internal/courses/:id/curriculum)
+// Autosave editor: menerima seluruh tree chapter/lesson, lalu create/update/
+// delete/reorder dijalankan dalam satu transaksi. Jika ada node yang tidak
+// valid, tidak ada yang disimpan dan respons berisi error per node.
+// If-Match berisi ETag course (versi outline); ETag respons dipakai untuk
+// autosave berikutnya.
+func (h *CourseHandler) SaveCurriculum(c *gin.Context) {
+	// 1. Ambil Course ID dari URL
+	courseIDStr := c.Param("id")
+	courseID, err := uuid.Parse(courseIDStr)
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
 	}
-	if input.Order != nil {
-		lesson.Order = *input.Order
+
+	// 2. Bind JSON body (tree lengkap)
+	var input struct {
+		Chapters []repository.CurriculumChapterInput `json:"chapters" binding:"required"`
 	}
-	if input.PlaybackID != nil {
-		lesson.PlaybackID = *input.PlaybackID
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
 	}
-	if input.IsPreview != nil {
-		lesson.IsPreview = *input.IsPreview
+
+	// 2b. Versi course yang dilihat editor (wajib): tab yang usang tidak
+	//     boleh menimpa perubahan tab lain
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
 	}
 
-	// 7. Simpan ke DB
-	updatedLesson, err := h.repo.UpdateLesson(c.Request.Context(), lesson)
+	// 3. Simpan
+	result, err := h.service.SaveCurriculum(c.Request.Context(), courseID, version, input.Chapters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lesson"})
+		// Versi usang -> 412 + course (tree) terkini;
+		// INVALID_CURRICULUM membawa detail per node di 'errors'
+		respondUpdateError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedLesson)
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/wtppaul/course-service/internal/middleware"
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+func TestListableStatuses(t *testing.T) {
+	student := middleware.Principal{AuthID: "student-1"}
+	teacher := middleware.Principal{AuthID: "teacher-1", Roles: []models.Role{models.RoleTeacher}}
+	curator := middleware.Principal{AuthID: "curator-1", Roles: []models.Role{models.RoleCurator}}
+	service := middleware.Principal{KeyID: "bff"}
+
+	tests := []struct {
+		name      string
+		principal middleware.Principal
+		requested []string
+		want      []string
+		wantErr   error
+	}{
+		{name: "default is published only", principal: student, want: []string{"PUBLISHED"}},
+		{name: "service default is published only", principal: service, want: []string{"PUBLISHED"}},
+		{name: "explicit published", principal: teacher, requested: []string{"PUBLISHED"}, want: []string{"PUBLISHED"}},
+		{name: "teacher asks for drafts", principal: teacher, requested: []string{"DRAFT"}, wantErr: middleware.ErrForbidden},
+		{name: "mixed statuses", principal: service, requested: []string{"PUBLISHED", "ARCHIVED"}, wantErr: middleware.ErrForbidden},
+		{name: "curator sees all by default", principal: curator, want: nil},
+		{name: "curator filters pending", principal: curator, requested: []string{"PENDING"}, want: []string{"PENDING"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listableStatuses(tt.principal, tt.requested)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("statuses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
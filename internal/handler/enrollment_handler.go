@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/problem"
+	"github.com/wtppaul/course-service/internal/repository"
+	"github.com/wtppaul/course-service/internal/service"
+)
+
+// === HANDLER ENROLLMENT (dipanggil oleh Payment-service & BFF) ===
+
+// GrantEnrollment (POST /internal/enrollments)
+// Memberi akses course ke student. Aman dipanggil ulang: enrollment
+// aktif yang sudah ada diperpanjang & memakai source/reference terbaru.
+func (h *CourseHandler) GrantEnrollment(c *gin.Context) {
+	// 1. Bind JSON body
+	var input struct {
+		AuthID    string                  `json:"authId" binding:"required"`
+		CourseID  uuid.UUID               `json:"courseId" binding:"required"`
+		Source    models.EnrollmentSource `json:"source" binding:"required"`
+		Reference string                  `json:"reference"`
+		ExpiresAt *time.Time              `json:"expiresAt"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	// 2. Validasi source/expiresAt & simpan
+	enrollment, created, err := h.service.GrantEnrollment(c.Request.Context(), repository.GrantEnrollmentInput{
+		AuthID:    input.AuthID,
+		CourseID:  input.CourseID,
+		Source:    input.Source,
+		Reference: input.Reference,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, enrollment)
+}
+
+// RevokeEnrollment (POST /internal/enrollments/revoke)
+// Mencabut akses (misal refund). Riwayat enrollment tetap disimpan.
+func (h *CourseHandler) RevokeEnrollment(c *gin.Context) {
+	var input struct {
+		AuthID   string    `json:"authId" binding:"required"`
+		CourseID uuid.UUID `json:"courseId" binding:"required"`
+		Reason   string    `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	enrollment, err := h.service.RevokeEnrollment(c.Request.Context(), input.AuthID, input.CourseID, input.Reason)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// GetCourseAccess (GET /internal/courses/:id/access?lessonId=...)
+// Dipakai BFF sebelum memutar video: apakah user boleh menonton lesson ini?
+// Tanpa 'lessonId', hasilnya akses level course (lesson non-preview).
+func (h *CourseHandler) GetCourseAccess(c *gin.Context) {
+	// 1. Parsing parameter
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+	input := service.AccessInput{
+		CourseID: courseID,
+		AuthID:   subjectAuthID(c, c.Query("authId")), // Hanya admin/service yang boleh mengecek user lain
+	}
+	if lessonIDStr := c.Query("lessonId"); lessonIDStr != "" {
+		lessonID, err := uuid.Parse(lessonIDStr)
+		if err != nil {
+			problem.Respond(c, problem.InvalidID("lessonId"))
+			return
+		}
+		input.LessonID = &lessonID
+	}
+
+	// 2. Putuskan (kepemilikan, enrollment, status course, preview)
+	access, err := h.service.CheckAccess(c.Request.Context(), input)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	response := gin.H{
+		"courseId": access.CourseID,
+		"authId":   access.AuthID,
+		"allowed":  access.Allowed,
+		"reason":   access.Reason,
+	}
+	if access.LessonID != nil {
+		response["lessonId"] = *access.LessonID
+	}
+	if access.Enrollment != nil {
+		response["enrollment"] = access.Enrollment
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,11 @@
+package handler
+
+import "github.com/wtppaul/course-service/internal/apperr"
+
+// Error level HTTP (error domain ada di package repository & service;
+// semuanya dikirim lewat problem.Respond)
+var (
+	errBatchTooLarge   = apperr.NewError(apperr.KindInvalidInput, "BATCH_TOO_LARGE", "too many items in batch")
+	errIfMatchRequired = apperr.NewError(apperr.KindPreconditionRequired, "IF_MATCH_REQUIRED", "If-Match header is required")
+	errInvalidIfMatch  = apperr.NewError(apperr.KindInvalidInput, "INVALID_IF_MATCH", "invalid If-Match header")
+)
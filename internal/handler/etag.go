@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wtppaul/course-service/internal/problem"
+	"github.com/wtppaul/course-service/internal/repository"
+	"github.com/wtppaul/course-service/internal/service"
+)
+
+// === OPTIMISTIC CONCURRENCY (ETag / If-Match) ===
+// ETag adalah kolom 'version' baris (misal: "7"). Client wajib mengirim
+// If-Match berisi ETag terakhir saat PATCH; jika sudah usang -> 412.
+
+// formatETag mengubah versi menjadi nilai header ETag
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag memasang header ETag untuk versi representasi yang dikirim
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", formatETag(version))
+}
+
+// requireIfMatch membaca versi dari header If-Match.
+// Hanya satu ETag kuat ("7") yang diterima: "*" akan melewati cek versi
+// dan validator lemah (W/"7") tidak boleh dipakai untuk If-Match (RFC 9110
+// 13.1.1, perbandingan kuat). Jika header tidak ada atau tidak valid,
+// respons 428/400 sudah dikirim dan ok = false.
+func requireIfMatch(c *gin.Context) (version int64, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		problem.Respond(c, errIfMatchRequired)
+		return 0, false
+	}
+
+	version, err := parseStrongETag(header)
+	if err != nil {
+		problem.Respond(c, errInvalidIfMatch)
+		return 0, false
+	}
+	return version, true
+}
+
+// parseStrongETag mengubah ETag kuat ("7") menjadi versi (>= 1)
+func parseStrongETag(value string) (int64, error) {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+// respondStale mengirim 412 beserta representasi terkini (dan ETag-nya)
+// agar client bisa menggabungkan perubahan lalu mencoba lagi
+func respondStale(c *gin.Context, version int64, current interface{}) {
+	setETag(c, version)
+	problem.Respond(c, repository.ErrVersionConflict.With("current", current))
+}
+
+// respondUpdateError: versi usang -> 412 beserta representasi terkini,
+// error lain -> problem.Respond
+func respondUpdateError(c *gin.Context, err error) {
+	var stale *service.VersionConflictError
+	if errors.As(err, &stale) {
+		respondStale(c, stale.Version, stale.Current)
+		return
+	}
+	problem.Respond(c, err)
+}
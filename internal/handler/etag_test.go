@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		header      string // "" = header tidak dikirim
+		wantOK      bool
+		wantVersion int64
+		wantStatus  int
+	}{
+		{name: "strong etag", header: `"7"`, wantOK: true, wantVersion: 7},
+		{name: "surrounding spaces", header: ` "12" `, wantOK: true, wantVersion: 12},
+		{name: "missing", header: "", wantStatus: http.StatusPreconditionRequired},
+		{name: "wildcard bypasses version check", header: "*", wantStatus: http.StatusBadRequest},
+		{name: "weak validator", header: `W/"7"`, wantStatus: http.StatusBadRequest},
+		{name: "unquoted", header: "7", wantStatus: http.StatusBadRequest},
+		{name: "multiple etags", header: `"6", "7"`, wantStatus: http.StatusBadRequest},
+		{name: "zero version", header: `"0"`, wantStatus: http.StatusBadRequest},
+		{name: "negative version", header: `"-1"`, wantStatus: http.StatusBadRequest},
+		{name: "not a number", header: `"abc"`, wantStatus: http.StatusBadRequest},
+		{name: "empty quotes", header: `""`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/internal/courses/x", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+
+			version, ok := requireIfMatch(c)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v (status %d, body %s)", ok, tt.wantOK, w.Code, w.Body)
+			}
+			if ok {
+				if version != tt.wantVersion {
+					t.Fatalf("version = %d, want %d", version, tt.wantVersion)
+				}
+				return
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
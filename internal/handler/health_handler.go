@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// DefaultHealthCheckTimeout adalah batas waktu ping per dependency
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// DependencyStatus adalah hasil ping satu dependency
+type DependencyStatus struct {
+	Status    string  `json:"status"` // UP / DOWN
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthHandler melayani probe Kubernetes:
+//   - /livez: proses hidup (tidak menyentuh dependency, agar pod tidak
+//     di-restart hanya karena Postgres/Redis sedang bermasalah)
+//   - /readyz: siap menerima traffic (Postgres & Redis bisa di-ping,
+//     dan server tidak sedang shutdown)
+type HealthHandler struct {
+	db           *gorm.DB
+	redis        *redis.Client
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(db *gorm.DB, redisClient *redis.Client, timeout time.Duration) *HealthHandler {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	return &HealthHandler{db: db, redis: redisClient, timeout: timeout}
+}
+
+// MarkShuttingDown membuat /readyz mengembalikan 503 agar load balancer
+// berhenti mengirim request baru selama request yang berjalan di-drain
+func (h *HealthHandler) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Livez (GET /livez)
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "UP", "service": "course-service"})
+}
+
+// Readyz (GET /readyz)
+// Ping semua dependency secara paralel, masing-masing dengan timeout.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	checks := map[string]func(ctx context.Context) error{
+		"postgres": h.pingPostgres,
+		"redis":    h.pingRedis,
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]DependencyStatus, len(checks))
+		ready   = true
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := DependencyStatus{
+				Status:    "UP",
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = "DOWN"
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = result
+			if err != nil {
+				ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	status, code := "READY", http.StatusOK
+	switch {
+	case h.shuttingDown.Load():
+		status, code = "SHUTTING_DOWN", http.StatusServiceUnavailable
+	case !ready:
+		status, code = "NOT_READY", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "service": "course-service", "checks": results})
+}
+
+func (h *HealthHandler) pingPostgres(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (h *HealthHandler) pingRedis(ctx context.Context) error {
+	return h.redis.Ping(ctx).Err()
+}
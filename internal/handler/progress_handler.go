@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/middleware"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/problem"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// MaxProgressBatchSize membatasi jumlah item per batch progress
+const MaxProgressBatchSize = 50
+
+// === HANDLER PROGRESS (dipanggil oleh player via BFF) ===
+
+// subjectAuthID menentukan user yang datanya dibaca/ditulis (progress,
+// akses lesson). User biasa selalu memakai identitasnya sendiri; 'authId'
+// dari query/body hanya dipakai untuk panggilan service internal atau admin.
+func subjectAuthID(c *gin.Context, explicit string) string {
+	principal := middleware.PrincipalFrom(c)
+	if explicit != "" && (principal.IsService() || principal.HasRole(models.RoleAdmin)) {
+		return explicit
+	}
+	return principal.AuthID
+}
+
+// UpsertLessonProgress (POST /internal/progress/batch)
+// Dipanggil player setiap beberapa detik dengan posisi terakhir tiap lesson.
+func (h *CourseHandler) UpsertLessonProgress(c *gin.Context) {
+	// 1. Bind JSON body
+	var input struct {
+		AuthID string `json:"authId"`
+		Items  []struct {
+			LessonID        uuid.UUID  `json:"lessonId" binding:"required"`
+			PositionSeconds int        `json:"positionSeconds" binding:"min=0"`
+			PercentWatched  *float64   `json:"percentWatched" binding:"omitempty,min=0,max=100"`
+			Completed       bool       `json:"completed"`
+			RecordedAt      *time.Time `json:"recordedAt"`
+		} `json:"items" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+	if len(input.Items) > MaxProgressBatchSize {
+		problem.Respond(c, errBatchTooLarge.With("max", MaxProgressBatchSize))
+		return
+	}
+
+	// 2. Simpan
+	items := make([]repository.LessonProgressInput, len(input.Items))
+	for i, item := range input.Items {
+		items[i] = repository.LessonProgressInput{
+			LessonID:        item.LessonID,
+			PositionSeconds: item.PositionSeconds,
+			PercentWatched:  item.PercentWatched,
+			Completed:       item.Completed,
+			RecordedAt:      item.RecordedAt,
+		}
+	}
+
+	progress, err := h.service.SaveProgress(c.Request.Context(), subjectAuthID(c, input.AuthID), items)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// GetCourseProgress (GET /internal/courses/:id/progress?authId=...)
+// Ringkasan progress + lesson berikutnya untuk tombol "Lanjutkan"
+func (h *CourseHandler) GetCourseProgress(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+	summary, err := h.service.CourseProgress(c.Request.Context(), subjectAuthID(c, c.Query("authId")), courseID)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
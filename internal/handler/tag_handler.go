@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/problem"
+)
+
+// === HANDLER TAG (via BFF) ===
+
+// CreateTag (POST /internal/tags)
+func (h *CourseHandler) CreateTag(c *gin.Context) {
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	tag, err := h.service.CreateTag(c.Request.Context(), input.Name)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// GetTags (GET /internal/tags?page=1&limit=50)
+func (h *CourseHandler) GetTags(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = DefaultPage
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	tags, total, err := h.service.Tags(c.Request.Context(), page, limit)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tags,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// SuggestTags (GET /internal/tags/suggest?q=go&limit=10)
+// Autocomplete berdasarkan awalan, urut dari tag yang paling sering dipakai
+func (h *CourseHandler) SuggestTags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	tags, err := h.service.SuggestTags(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// RenameTag (PATCH /internal/tags/:id)
+func (h *CourseHandler) RenameTag(c *gin.Context) {
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	updatedTag, err := h.service.RenameTag(c.Request.Context(), tagID, input.Name)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedTag)
+}
+
+// MergeTag (POST /internal/tags/:id/merge)
+// Body: { "targetId": "uuid" } — tag :id (duplikat) digabung ke targetId (kanonik)
+func (h *CourseHandler) MergeTag(c *gin.Context) {
+	sourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("id"))
+		return
+	}
+
+	var input struct {
+		TargetID uuid.UUID `json:"targetId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem.Respond(c, problem.InvalidBody(err))
+		return
+	}
+
+	moved, err := h.service.MergeTags(c.Request.Context(), sourceID, input.TargetID)
+	if err != nil {
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Tags merged successfully",
+		"targetId":     input.TargetID,
+		"movedCourses": moved,
+	})
+}
@@ -0,0 +1,290 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/problem"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// Error keputusan otorisasi (dikirim sebagai problem+json oleh Require)
+var (
+	ErrUnauthenticated = apperr.NewError(apperr.KindUnauthenticated, "UNAUTHENTICATED", "missing user context")
+	ErrForbidden       = apperr.NewError(apperr.KindForbidden, "INSUFFICIENT_ROLE", "forbidden: insufficient role")
+	ErrNotOwner        = repository.ErrNotOwner
+)
+
+// Principal adalah pemanggil request: user (via gateway) atau service lain
+// (request bertanda tangan tanpa X-Authenticated-User-ID)
+type Principal struct {
+	AuthID string
+	Roles  []models.Role
+	KeyID  string // Signing key pemanggil (mengidentifikasi service/BFF)
+}
+
+// IsService: request dari service internal (Payment-service, dll.), bukan user
+func (p Principal) IsService() bool {
+	return p.AuthID == ""
+}
+
+// HasRole mengecek apakah principal memiliki salah satu role
+func (p Principal) HasRole(roles ...models.Role) bool {
+	return models.HasRole(p.Roles, roles...)
+}
+
+// PrincipalFrom membaca principal yang di-set InternalAuthMiddleware
+func PrincipalFrom(c *gin.Context) Principal {
+	p := Principal{
+		AuthID: c.GetString("authenticatedUserID"),
+		KeyID:  c.GetString("authenticatedKeyID"),
+	}
+	if roles, ok := c.Get("authenticatedUserRoles"); ok {
+		p.Roles, _ = roles.([]models.Role)
+	}
+	return p
+}
+
+// Policy memutuskan apakah principal boleh mengakses satu route.
+// Kembalikan nil jika boleh.
+type Policy func(c *gin.Context, p Principal, repo repository.ICourseRepository) error
+
+// CourseResolver mencari course yang menjadi target route (dari parameter URL)
+type CourseResolver func(c *gin.Context, repo repository.ICourseRepository) (uuid.UUID, error)
+
+// Authorizer memasang policy per route. Setiap route di /internal harus
+// mendeklarasikan policy-nya di routes, agar aturan akses terlihat di satu tempat.
+type Authorizer struct {
+	repo repository.ICourseRepository
+}
+
+func NewAuthorizer(repo repository.ICourseRepository) *Authorizer {
+	return &Authorizer{repo: repo}
+}
+
+// Require membuat middleware yang menjalankan 'policy' sebelum handler
+func (a *Authorizer) Require(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Resolver mengembalikan error domain (COURSE_NOT_FOUND, dll.)
+		if err := policy(c, PrincipalFrom(c), a.repo); err != nil {
+			problem.Respond(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// --- Policy ---
+
+// Public: semua pemanggil yang lolos verifikasi signature
+// (halaman publik via BFF, service lain)
+func Public() Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		return nil
+	}
+}
+
+// Authenticated: harus ada user (role apa pun), misal progress siswa
+func Authenticated() Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		if p.IsService() {
+			return ErrUnauthenticated
+		}
+		return nil
+	}
+}
+
+// UserOrService: user (role apa pun), atau service internal yang menyebut
+// user yang dimaksud lewat query 'subjectParam' (misal BFF mengecek akses
+// ?authId=...). Tanpa subject, panggilan service tidak punya user untuk dicek.
+func UserOrService(subjectParam string) Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		if p.IsService() && c.Query(subjectParam) == "" {
+			return ErrUnauthenticated
+		}
+		return nil
+	}
+}
+
+// AnyRole: user dengan salah satu role
+func AnyRole(roles ...models.Role) Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		if p.IsService() {
+			return ErrUnauthenticated
+		}
+		if !p.HasRole(roles...) {
+			return ErrForbidden
+		}
+		return nil
+	}
+}
+
+// ServiceOrRole: service internal, atau user dengan salah satu role
+// (misal enrollment: Payment-service atau admin)
+func ServiceOrRole(roles ...models.Role) Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		if p.IsService() || p.HasRole(roles...) {
+			return nil
+		}
+		return ErrForbidden
+	}
+}
+
+// CourseOwnerOr: teacher pemilik course, atau user dengan salah satu role.
+// Contoh: CourseOwnerOr(CourseParam("id"), models.RoleAdmin) = "owner atau admin"
+func CourseOwnerOr(resolve CourseResolver, roles ...models.Role) Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		if p.IsService() {
+			return ErrUnauthenticated
+		}
+
+		// 1. Resolve course dulu (404 untuk semua role jika tidak ada)
+		courseID, err := resolve(c, repo)
+		if err != nil {
+			return err
+		}
+		if p.HasRole(roles...) {
+			return nil
+		}
+
+		// 2. Selain itu harus pemilik course
+		return requireCourseOwner(c, p, repo, courseID)
+	}
+}
+
+// PublishedOr: course PUBLISHED boleh dibaca semua pemanggil; status lain
+// (DRAFT, PENDING, ARCHIVED, ...) hanya untuk pemilik atau user dengan salah
+// satu role. Contoh: PublishedOr(CourseParam("id"), models.RoleCurator, models.RoleAdmin)
+func PublishedOr(resolve CourseResolver, roles ...models.Role) Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		// 1. Resolve course (404 untuk semua pemanggil jika tidak ada)
+		courseID, err := resolve(c, repo)
+		if err != nil {
+			return err
+		}
+		course, err := repo.GetCourseByID(c.Request.Context(), courseID)
+		if err != nil {
+			return err
+		}
+		if course.Status == models.StatusPublished {
+			return nil
+		}
+
+		// 2. Belum/tidak dipublikasikan: hanya role tertentu atau pemilik
+		if p.IsService() {
+			return ErrUnauthenticated
+		}
+		if p.HasRole(roles...) {
+			return nil
+		}
+		return requireCourseOwner(c, p, repo, courseID)
+	}
+}
+
+// TeacherSelfOr: teacher yang bersangkutan (parameter teacher ID di URL),
+// atau user dengan salah satu role
+func TeacherSelfOr(param string, roles ...models.Role) Policy {
+	return func(c *gin.Context, p Principal, repo repository.ICourseRepository) error {
+		if p.IsService() {
+			return ErrUnauthenticated
+		}
+		teacherID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return problem.InvalidID(param)
+		}
+		if p.HasRole(roles...) {
+			return nil
+		}
+		if !p.HasRole(models.RoleTeacher) {
+			return ErrForbidden
+		}
+
+		isSelf, err := repo.IsTeacherAuthID(c.Request.Context(), teacherID, p.AuthID)
+		if err != nil {
+			return err
+		}
+		if !isSelf {
+			return ErrForbidden
+		}
+		return nil
+	}
+}
+
+// requireCourseOwner: principal harus teacher pemilik course
+func requireCourseOwner(c *gin.Context, p Principal, repo repository.ICourseRepository, courseID uuid.UUID) error {
+	if !p.HasRole(models.RoleTeacher) {
+		return ErrForbidden
+	}
+	isOwner, err := repo.IsCourseOwner(c.Request.Context(), courseID, p.AuthID)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// --- Resolver course ---
+
+// CourseParam: course ID langsung dari parameter URL (misal :id)
+func CourseParam(param string) CourseResolver {
+	return func(c *gin.Context, repo repository.ICourseRepository) (uuid.UUID, error) {
+		courseID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return uuid.Nil, problem.InvalidID(param)
+		}
+		return courseID, ensureCourseExists(c.Request.Context(), repo, courseID)
+	}
+}
+
+// CourseSlugParam: course dari slug di parameter URL (misal :slug)
+func CourseSlugParam(param string) CourseResolver {
+	return func(c *gin.Context, repo repository.ICourseRepository) (uuid.UUID, error) {
+		course, err := repo.GetCourseBySlug(c.Request.Context(), c.Param(param))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return course.ID, nil
+	}
+}
+
+// ChapterParam: course pemilik chapter di parameter URL (misal :chapterId)
+func ChapterParam(param string) CourseResolver {
+	return func(c *gin.Context, repo repository.ICourseRepository) (uuid.UUID, error) {
+		chapterID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return uuid.Nil, problem.InvalidID(param)
+		}
+		chapter, err := repo.GetChapterByID(c.Request.Context(), chapterID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return chapter.CourseID, nil
+	}
+}
+
+// LessonParam: course pemilik lesson di parameter URL (misal :lessonId)
+func LessonParam(param string) CourseResolver {
+	return func(c *gin.Context, repo repository.ICourseRepository) (uuid.UUID, error) {
+		lessonID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			return uuid.Nil, problem.InvalidID(param)
+		}
+		// (lesson.CourseID didapat dari 'Join' di GetLessonByID)
+		lesson, err := repo.GetLessonByID(c.Request.Context(), lessonID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return lesson.CourseID, nil
+	}
+}
+
+func ensureCourseExists(ctx context.Context, repo repository.ICourseRepository, courseID uuid.UUID) error {
+	_, err := repo.GetCourseByID(ctx, courseID)
+	return err
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// policyTestRepo hanya mengimplementasikan method yang dipakai policy;
+// method lain panic lewat interface nil yang di-embed
+type policyTestRepo struct {
+	repository.ICourseRepository
+	courses map[uuid.UUID]*models.Course
+	owners  map[uuid.UUID]string // courseID -> authID teacher
+}
+
+func (r *policyTestRepo) GetCourseByID(ctx context.Context, courseID uuid.UUID) (*models.Course, error) {
+	if course, ok := r.courses[courseID]; ok {
+		return course, nil
+	}
+	return nil, repository.ErrCourseNotFound
+}
+
+func (r *policyTestRepo) GetCourseBySlug(ctx context.Context, slug string) (*models.Course, error) {
+	for _, course := range r.courses {
+		if course.Slug == slug {
+			return course, nil
+		}
+	}
+	return nil, repository.ErrCourseNotFound
+}
+
+func (r *policyTestRepo) IsCourseOwner(ctx context.Context, courseID uuid.UUID, authID string) (bool, error) {
+	return r.owners[courseID] == authID, nil
+}
+
+func TestPublishedOr(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	published := &models.Course{ID: uuid.New(), Slug: "go-dasar", Status: models.StatusPublished}
+	draft := &models.Course{ID: uuid.New(), Slug: "go-lanjut", Status: models.StatusDraft}
+	repo := &policyTestRepo{
+		courses: map[uuid.UUID]*models.Course{published.ID: published, draft.ID: draft},
+		owners:  map[uuid.UUID]string{published.ID: "teacher-1", draft.ID: "teacher-1"},
+	}
+
+	teacher := Principal{AuthID: "teacher-1", Roles: []models.Role{models.RoleTeacher}}
+	otherTeacher := Principal{AuthID: "teacher-2", Roles: []models.Role{models.RoleTeacher}}
+	curator := Principal{AuthID: "curator-1", Roles: []models.Role{models.RoleCurator}}
+	student := Principal{AuthID: "student-1"} // Tanpa role yang dikenal
+	service := Principal{KeyID: "payment-service"}
+
+	tests := []struct {
+		name      string
+		resolve   CourseResolver
+		param     gin.Param
+		principal Principal
+		wantErr   error
+	}{
+		{name: "published for service", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: published.ID.String()}, principal: service},
+		{name: "published for student", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: published.ID.String()}, principal: student},
+		{name: "published by slug", resolve: CourseSlugParam("slug"), param: gin.Param{Key: "slug", Value: "go-dasar"}, principal: student},
+		{name: "draft for owner", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: draft.ID.String()}, principal: teacher},
+		{name: "draft for curator", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: draft.ID.String()}, principal: curator},
+		{name: "draft for other teacher", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: draft.ID.String()}, principal: otherTeacher, wantErr: ErrNotOwner},
+		{name: "draft for student", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: draft.ID.String()}, principal: student, wantErr: ErrForbidden},
+		{name: "draft for service", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: draft.ID.String()}, principal: service, wantErr: ErrUnauthenticated},
+		{name: "draft by slug for student", resolve: CourseSlugParam("slug"), param: gin.Param{Key: "slug", Value: "go-lanjut"}, principal: student, wantErr: ErrForbidden},
+		{name: "unknown course", resolve: CourseParam("id"), param: gin.Param{Key: "id", Value: uuid.NewString()}, principal: curator, wantErr: repository.ErrCourseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/internal/courses/x", nil)
+			c.Params = gin.Params{tt.param}
+
+			err := PublishedOr(tt.resolve, models.RoleCurator, models.RoleAdmin)(c, tt.principal, repo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticated(t *testing.T) {
+	policy := Authenticated()
+
+	if err := policy(nil, Principal{KeyID: "payment-service"}, nil); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("service call err = %v, want %v", err, ErrUnauthenticated)
+	}
+	// Role tidak diperlukan: siswa tanpa role tetap boleh
+	if err := policy(nil, Principal{AuthID: "student-1"}, nil); err != nil {
+		t.Fatalf("user call err = %v, want nil", err)
+	}
+}
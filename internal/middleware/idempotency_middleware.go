@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/problem"
+)
+
+const (
+	idempotencyKeyPrefix  = "course-service:idempotency"
+	idempotencyMaxKeyLen  = 255
+	idempotencyLockTTL    = 30 * time.Second // Batas waktu request pertama selesai
+	idempotencyProcessing = "processing"
+)
+
+// Penolakan Idempotency-Key
+var (
+	errIdempotencyKeyTooLong = apperr.NewError(apperr.KindInvalidInput, "IDEMPOTENCY_KEY_TOO_LONG", "Idempotency-Key is too long")
+	errIdempotencyInProgress = apperr.NewError(apperr.KindConflict, "IDEMPOTENCY_IN_PROGRESS", "request with this Idempotency-Key is still being processed")
+	errIdempotencyKeyReused  = apperr.NewError(apperr.KindUnprocessable, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used with a different request")
+)
+
+// idempotencyRecord adalah respons yang disimpan untuk di-replay
+type idempotencyRecord struct {
+	State       string              `json:"state"`       // "processing" atau "done"
+	Fingerprint string              `json:"fingerprint"` // sha256(method, path, body)
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// Header respons yang ikut disimpan & di-replay
+var idempotencyReplayHeaders = []string{"Content-Type", "Content-Language", "ETag", "Location"}
+
+// bodyRecorder menyalin body respons sambil tetap menulisnya ke client
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware menangani header Idempotency-Key untuk request yang
+// mengubah data (POST/PUT/PATCH/DELETE). Dipasang setelah InternalAuthMiddleware.
+//
+//   - Request pertama dijalankan; respons (< 500) disimpan di Redis selama 'ttl'
+//   - Retry dengan key & body yang sama -> respons tersimpan di-replay
+//   - Key yang sama dengan body/endpoint berbeda -> 422
+//   - Retry saat request pertama masih berjalan -> 409
+//
+// Key di-scope per pemanggil (signing key + X-Authenticated-User-ID), jadi
+// dua service tanpa user tidak berbagi namespace. Tanpa header, request
+// diteruskan apa adanya. Jika Redis bermasalah, request juga diteruskan
+// (lebih baik tetap melayani daripada menolak semua penulisan).
+func IdempotencyMiddleware(client *redis.Client, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if client == nil || key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeyLen {
+			problem.Respond(c, errIdempotencyKeyTooLong.With("max", idempotencyMaxKeyLen))
+			return
+		}
+
+		// 1. Baca body (lalu kembalikan agar bisa di-bind handler)
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			problem.Respond(c, problem.ErrInvalidBody.Wrap(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		principal := PrincipalFrom(c)
+		redisKey := idempotencyRedisKey(principal.KeyID, principal.AuthID, key)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		// 2. Klaim key (SET NX). Gagal = sudah pernah dipakai
+		lock, _ := json.Marshal(idempotencyRecord{State: idempotencyProcessing, Fingerprint: fingerprint})
+		claimed, err := client.SetNX(ctx, redisKey, lock, idempotencyLockTTL).Result()
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "idempotency: failed to claim key", "error", err)
+			c.Next()
+			return
+		}
+
+		if !claimed {
+			record, err := loadIdempotencyRecord(ctx, client, redisKey)
+			if err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "idempotency: failed to read key", "error", err)
+				problem.Respond(c, errIdempotencyInProgress)
+				return
+			}
+			switch {
+			case record.Fingerprint != "" && record.Fingerprint != fingerprint:
+				problem.Respond(c, errIdempotencyKeyReused)
+			case record.State == idempotencyProcessing:
+				problem.Respond(c, errIdempotencyInProgress)
+			default:
+				replayIdempotentResponse(c, record)
+			}
+			return
+		}
+
+		// Pakai context baru untuk menyimpan/melepas key: request context
+		// bisa sudah dibatalkan
+		storeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		release := func() {
+			if err := client.Del(storeCtx, redisKey).Err(); err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "idempotency: failed to release key", "error", err)
+			}
+		}
+
+		// 3. Jalankan handler sambil merekam respons. Jika handler panic, key
+		//    dilepas dulu (bukan menunggu lock TTL) lalu panic diteruskan ke
+		//    RecoveryMiddleware
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					release()
+					panic(r)
+				}
+			}()
+			c.Next()
+		}()
+
+		// 4. Simpan hasilnya; error server tidak disimpan agar retry dijalankan ulang
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		record := idempotencyRecord{
+			State:       "done",
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      map[string][]string{},
+			Body:        recorder.body.Bytes(),
+		}
+		for _, name := range idempotencyReplayHeaders {
+			if values := recorder.Header().Values(name); len(values) > 0 {
+				record.Header[name] = values
+			}
+		}
+		data, err := json.Marshal(record)
+		if err == nil {
+			err = client.Set(storeCtx, redisKey, data, ttl).Err()
+		}
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "idempotency: failed to store response", "error", err)
+		}
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyRedisKey membentuk key Redis per (signing key, user, key).
+// Komponen di-hash agar ':' di dalam user ID/key tidak bisa membuat dua
+// pemanggil berbagi key (header HTTP tidak boleh berisi byte NUL).
+func idempotencyRedisKey(keyID, authID, key string) string {
+	scope := sha256.Sum256([]byte(keyID + "\x00" + authID + "\x00" + key))
+	return idempotencyKeyPrefix + ":" + hex.EncodeToString(scope[:])
+}
+
+// requestFingerprint: key yang sama hanya boleh dipakai untuk request yang identik
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func loadIdempotencyRecord(ctx context.Context, client *redis.Client, key string) (*idempotencyRecord, error) {
+	data, err := client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// Kedaluwarsa di antara SETNX & GET; anggap masih diproses
+			// (fingerprint kosong = tidak diketahui)
+			return &idempotencyRecord{State: idempotencyProcessing}, nil
+		}
+		return nil, err
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func replayIdempotentResponse(c *gin.Context, record *idempotencyRecord) {
+	for name, values := range record.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Status(record.Status)
+	c.Writer.Write(record.Body)
+	c.Abort()
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyTestRouter memasang IdempotencyMiddleware di belakang recovery
+// dan "auth" palsu yang membaca key ID & user dari header test
+func idempotencyTestRouter(t *testing.T, handler gin.HandlerFunc) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	router := gin.New()
+	router.Use(RecoveryMiddleware())
+	router.Use(func(c *gin.Context) {
+		c.Set("authenticatedKeyID", c.GetHeader("X-Test-Key-Id"))
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("authenticatedUserID", userID)
+		}
+	})
+	router.Use(IdempotencyMiddleware(client, time.Hour))
+	router.POST("/internal/things", handler)
+	return router, mr
+}
+
+func doIdempotent(router *gin.Engine, keyID, userID, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/internal/things", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	req.Header.Set("X-Test-Key-Id", keyID)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysSameRequest(t *testing.T) {
+	var calls atomic.Int32
+	router, _ := idempotencyTestRouter(t, func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+
+	first := doIdempotent(router, "bff", "user-1", "k1", `{"a":1}`)
+	second := doIdempotent(router, "bff", "user-1", "k1", `{"a":1}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("replayed response is missing Idempotent-Replayed header")
+	}
+
+	// Key sama, body berbeda -> 422
+	if w := doIdempotent(router, "bff", "user-1", "k1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key status = %d, want 422", w.Code)
+	}
+}
+
+func TestIdempotencyKeyScopedPerCaller(t *testing.T) {
+	var calls atomic.Int32
+	router, _ := idempotencyTestRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusNoContent)
+	})
+
+	callers := []struct{ keyID, userID string }{
+		{"payment-service", ""}, // Service tanpa user
+		{"reporting-job", ""},   // Service lain, key Idempotency yang sama
+		{"bff", "user-1"},
+		{"bff", "user-2"},
+		{"bff", "user"},     // "user" + "-1:..." tidak boleh bertabrakan
+		{"bff", "user-1:k"}, // dengan "user-1" + "k:..."
+	}
+	for _, caller := range callers {
+		if w := doIdempotent(router, caller.keyID, caller.userID, "order-42", `{}`); w.Code != http.StatusNoContent {
+			t.Fatalf("caller %+v status = %d, want 204", caller, w.Code)
+		}
+	}
+	if int(calls.Load()) != len(callers) {
+		t.Fatalf("handler called %d times, want %d (callers must not share keys)", calls.Load(), len(callers))
+	}
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	var calls atomic.Int32
+	router, mr := idempotencyTestRouter(t, func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	if w := doIdempotent(router, "bff", "user-1", "k1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking request status = %d, want 500", w.Code)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Fatalf("key still held after panic: %v", keys)
+	}
+
+	// Retry langsung dijalankan ulang (bukan 409 sampai lock TTL habis)
+	if w := doIdempotent(router, "bff", "user-1", "k1", `{}`); w.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want 201", w.Code)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler called %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	router, mr := idempotencyTestRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	doIdempotent(router, "bff", "user-1", "k1", `{}`)
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Fatalf("5xx response was stored: %v", keys)
+	}
+	doIdempotent(router, "bff", "user-1", "k1", `{}`)
+	if calls.Load() != 2 {
+		t.Fatalf("handler called %d times, want 2", calls.Load())
+	}
+}
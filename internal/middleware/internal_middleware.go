@@ -1,39 +1,134 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/problem"
 )
 
-// InternalAuthMiddleware memvalidasi X-Internal-Secret
-func InternalAuthMiddleware() gin.HandlerFunc {
-	// Ambil secret dari ENV sekali saja saat startup
-	// Ini JAUH lebih cepat daripada os.Getenv() di setiap request
-	internalSecret := os.Getenv("INTERNAL_API_SECRET")
+// DefaultSignatureWindow adalah selisih waktu maksimum antara timestamp
+// request dan jam server (dua arah), untuk membatasi replay
+const DefaultSignatureWindow = 5 * time.Minute
+
+// DefaultMaxBodyBytes adalah batas body request (body dibaca seluruhnya
+// untuk di-hash sebelum signature diverifikasi)
+const DefaultMaxBodyBytes = 1 << 20
+
+// Alasan penolakan request internal (403)
+var (
+	errSignatureKeyUnknown = apperr.NewError(apperr.KindForbidden, "SIGNATURE_KEY_UNKNOWN", "unknown signing key")
+	errSignatureTimestamp  = apperr.NewError(apperr.KindForbidden, "SIGNATURE_TIMESTAMP_INVALID", "invalid signature timestamp")
+	errSignatureExpired    = apperr.NewError(apperr.KindForbidden, "SIGNATURE_EXPIRED", "signature timestamp outside allowed window")
+	errSignatureInvalid    = apperr.NewError(apperr.KindForbidden, "SIGNATURE_INVALID", "invalid signature")
+	errBodyTooLarge        = apperr.NewError(apperr.KindPayloadTooLarge, "BODY_TOO_LARGE", "request body too large")
+	errUserRoleMissing     = apperr.NewError(apperr.KindUnauthenticated, "USER_ROLE_MISSING", "user ID sent without role header")
+)
 
-	if internalSecret == "" {
+// InternalAuthMiddleware memvalidasi request yang ditandatangani HMAC
+// (lihat CanonicalRequest) dengan key aktif. window adalah jendela replay
+// (<= 0 = DefaultSignatureWindow), maxBodyBytes batas body yang mau dibaca
+// (<= 0 = DefaultMaxBodyBytes; lebih besar -> 413).
+func InternalAuthMiddleware(keys SigningKeys, window time.Duration, maxBodyBytes int64) gin.HandlerFunc {
+	if len(keys) == 0 {
 		// Jika service tidak dikonfigurasi dengan benar,
 		// jangan pernah biarkan request apa pun masuk.
-		panic("FATAL: INTERNAL_API_SECRET is not set")
+		panic("FATAL: no internal signing keys configured")
+	}
+	if window <= 0 {
+		window = DefaultSignatureWindow
+	}
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
 	}
 
 	return func(c *gin.Context) {
-		secret := c.GetHeader("X-Internal-Secret")
+		forbidden := func(reason *apperr.Error) {
+			ctx := c.Request.Context()
+			logging.FromContext(ctx).WarnContext(ctx, "internal request rejected",
+				"reason", reason.Message,
+				"key_id", c.GetHeader(HeaderSignatureKeyID),
+			)
+			problem.Respond(c, reason)
+		}
+
+		// 1. Key ID harus dikenal
+		secret, ok := keys[c.GetHeader(HeaderSignatureKeyID)]
+		if !ok {
+			forbidden(errSignatureKeyUnknown)
+			return
+		}
+
+		// 2. Timestamp harus di dalam jendela replay
+		timestamp := c.GetHeader(HeaderSignatureTimestamp)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			forbidden(errSignatureTimestamp)
+			return
+		}
+		if skew := time.Since(time.Unix(unix, 0)); skew > window || skew < -window {
+			forbidden(errSignatureExpired)
+			return
+		}
 
-		if secret != internalSecret {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: Invalid internal secret"})
+		// 3. Baca body untuk di-hash (lalu kembalikan untuk handler).
+		//    Pemanggil belum terverifikasi: jangan buffer lebih dari batas
+		if c.Request.ContentLength > maxBodyBytes {
+			problem.Respond(c, errBodyTooLarge.With("max", maxBodyBytes))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.Respond(c, errBodyTooLarge.With("max", maxBodyBytes))
+				return
+			}
+			problem.Respond(c, problem.ErrInvalidBody.Wrap(err))
 			return
 		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 
-		// Jika secret valid, kita PERCAYA header X-Authenticated-User-ID
-		// yang dikirim oleh gateway.
-		userID := c.GetHeader("X-Authenticated-User-ID")
+		// 4. Verifikasi signature (constant-time)
+		userID := c.GetHeader(HeaderUserID)
+		userRole := c.GetHeader(HeaderUserRole)
+		canonical := CanonicalRequest(c.Request.Method, c.Request.URL.RequestURI(), timestamp, body, userID, userRole)
+		if !verifySignature(secret, canonical, c.GetHeader(HeaderSignature)) {
+			forbidden(errSignatureInvalid)
+			return
+		}
+
+		// Signature valid: header user ikut ditandatangani, jadi bisa dipercaya
+		c.Set("authenticatedKeyID", c.GetHeader(HeaderSignatureKeyID))
 		if userID != "" {
+			// Gateway selalu mengirim role bersama user ID; tanpa header role
+			// identitas user tidak lengkap
+			if strings.TrimSpace(userRole) == "" {
+				problem.Respond(c, errUserRoleMissing)
+				return
+			}
 			c.Set("authenticatedUserID", userID) // Set di context untuk handler
+
+			// Role pemanggil dari gateway, boleh lebih dari satu ("TEACHER,CURATOR").
+			// Role yang tidak dikenal diabaikan (tanpa role default): user tanpa
+			// role yang dikenal ditolak oleh policy berbasis role (403).
+			roles := models.ParseRoles(userRole)
+			c.Set("authenticatedUserRoles", roles)
+
+			// Semua log berikutnya untuk request ini ikut membawa user
+			addLogAttrs(c, "user_id", userID, "roles", roles)
 		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
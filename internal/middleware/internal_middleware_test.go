@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testSigningKeys = SigningKeys{"bff-1": []byte("s3cret")}
+
+// signedRequest membuat request bertanda tangan dengan key "bff-1"
+func signedRequest(method, uri, body, userID, userRole string) *http.Request {
+	req := httptest.NewRequest(method, uri, strings.NewReader(body))
+	headers := SignatureHeaders("bff-1", testSigningKeys["bff-1"], method, uri, []byte(body), userID, userRole, time.Now())
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if userID != "" {
+		req.Header.Set(HeaderUserID, userID)
+	}
+	if userRole != "" {
+		req.Header.Set(HeaderUserRole, userRole)
+	}
+	return req
+}
+
+// internalAuthTestRouter mengembalikan router dengan satu route yang
+// menggemakan principal & body
+func internalAuthTestRouter(maxBodyBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(InternalAuthMiddleware(testSigningKeys, time.Minute, maxBodyBytes))
+	router.POST("/internal/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		p := PrincipalFrom(c)
+		c.JSON(http.StatusOK, gin.H{"authId": p.AuthID, "roles": p.Roles, "keyId": p.KeyID, "bytes": len(body)})
+	})
+	return router
+}
+
+func TestInternalAuthMiddlewareSignature(t *testing.T) {
+	router := internalAuthTestRouter(0)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest(http.MethodPost, "/internal/echo", `{"a":1}`, "user-1", "TEACHER"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("valid signature status = %d, body %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `"keyId":"bff-1"`) {
+		t.Fatalf("key ID not exposed to principal: %s", w.Body)
+	}
+
+	// Body diubah setelah ditandatangani
+	req := signedRequest(http.MethodPost, "/internal/echo", `{"a":1}`, "user-1", "TEACHER")
+	req.Body = io.NopCloser(strings.NewReader(`{"a":2}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("tampered body status = %d, want 403", w.Code)
+	}
+}
+
+func TestInternalAuthMiddlewareBodyLimit(t *testing.T) {
+	const limit = 64
+	router := internalAuthTestRouter(limit)
+
+	tests := []struct {
+		name          string
+		body          string
+		unknownLength bool // Content-Length tidak dikirim (chunked)
+		wantStatus    int
+	}{
+		{name: "at limit", body: strings.Repeat("a", limit), wantStatus: http.StatusOK},
+		{name: "declared too large", body: strings.Repeat("a", limit+1), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked too large", body: strings.Repeat("a", limit*4), unknownLength: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked within limit", body: "small", unknownLength: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := signedRequest(http.MethodPost, "/internal/echo", tt.body, "user-1", "TEACHER")
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestInternalAuthMiddlewareRoles(t *testing.T) {
+	router := internalAuthTestRouter(0)
+
+	tests := []struct {
+		name       string
+		userID     string
+		userRole   string
+		wantStatus int
+		wantRoles  string
+	}{
+		{name: "known roles", userID: "user-1", userRole: "TEACHER,CURATOR", wantStatus: http.StatusOK, wantRoles: `"roles":["TEACHER","CURATOR"]`},
+		{name: "unknown role is not upgraded", userID: "user-1", userRole: "SUPERUSER", wantStatus: http.StatusOK, wantRoles: `"roles":null`},
+		{name: "unknown roles are dropped", userID: "user-1", userRole: "SUPERUSER,ADMIN", wantStatus: http.StatusOK, wantRoles: `"roles":["ADMIN"]`},
+		{name: "user without role header", userID: "user-1", userRole: "", wantStatus: http.StatusUnauthorized},
+		{name: "service call", userID: "", userRole: "", wantStatus: http.StatusOK, wantRoles: `"roles":null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, signedRequest(http.MethodPost, "/internal/echo", `{}`, tt.userID, tt.userRole))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantRoles != "" && !strings.Contains(w.Body.String(), tt.wantRoles) {
+				t.Fatalf("body %s does not contain %s", w.Body, tt.wantRoles)
+			}
+		})
+	}
+}
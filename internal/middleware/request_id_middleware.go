@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/problem"
+)
+
+// HeaderRequestID adalah header korelasi antar service
+const HeaderRequestID = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware memakai X-Request-ID dari gateway (atau membuat baru),
+// mengembalikannya di respons, dan memasang logger request (request_id,
+// method, route) di context. Dipasang paling awal.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set("requestID", requestID)
+		c.Header(HeaderRequestID, requestID)
+
+		logger := slog.Default().With(
+			"request_id", requestID,
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+		)
+		c.Request = c.Request.WithContext(logging.WithContext(c.Request.Context(), logger))
+
+		c.Next()
+	}
+}
+
+// isValidRequestID: hanya karakter aman (cegah log injection), panjang dibatasi
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// addLogAttrs menambahkan atribut ke logger request (misal user_id setelah
+// signature diverifikasi)
+func addLogAttrs(c *gin.Context, args ...any) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logging.WithContext(ctx, logging.FromContext(ctx).With(args...)))
+}
+
+// AccessLogMiddleware mencatat satu baris log per request setelah selesai
+// (pengganti logger stdout bawaan gin). Probe & /metrics dicatat di level debug.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case isProbePath(c.Request.URL.Path):
+			level = slog.LevelDebug
+		}
+
+		// Size() = -1 jika handler tidak menulis body
+		bytes := c.Writer.Size()
+		if bytes < 0 {
+			bytes = 0
+		}
+
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).Log(ctx, level, "request completed",
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", c.ClientIP(),
+			"bytes", bytes,
+		)
+	}
+}
+
+func isProbePath(path string) bool {
+	switch path {
+	case "/livez", "/readyz", "/health", "/metrics":
+		return true
+	}
+	return false
+}
+
+// RecoveryMiddleware mengubah panic menjadi 500 dan mencatatnya (dengan stack)
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		// Size() = -1 jika handler tidak menulis body
+		bytes := c.Writer.Size()
+		if bytes < 0 {
+			bytes = 0
+		}
+
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).ErrorContext(ctx, "panic recovered",
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		problem.Respond(c, problem.ErrInternal)
+	})
+}
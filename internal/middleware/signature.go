@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header request yang ditandatangani
+const (
+	HeaderSignatureKeyID     = "X-Signature-Key-Id"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp" // Unix detik
+	HeaderSignature          = "X-Signature"           // hex(HMAC-SHA256)
+	HeaderUserID             = "X-Authenticated-User-ID"
+	HeaderUserRole           = "X-Authenticated-User-Role" // Daftar role, dipisah koma
+)
+
+// SigningKeys adalah kunci HMAC aktif: key ID -> secret.
+// Beberapa key boleh aktif bersamaan agar rotasi tanpa downtime:
+// tambah key baru, pindahkan pemanggil ke key baru, lalu hapus key lama.
+type SigningKeys map[string][]byte
+
+// ParseSigningKeys membaca format "kid1:secret1,kid2:secret2"
+func ParseSigningKeys(raw string) (SigningKeys, error) {
+	keys := SigningKeys{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		keyID, secret, ok := strings.Cut(entry, ":")
+		keyID = strings.TrimSpace(keyID)
+		if !ok || keyID == "" || secret == "" {
+			return nil, fmt.Errorf("invalid signing key entry: expected KEY_ID:SECRET")
+		}
+		if _, exists := keys[keyID]; exists {
+			return nil, fmt.Errorf("duplicate signing key id %q", keyID)
+		}
+		keys[keyID] = []byte(secret)
+	}
+	return keys, nil
+}
+
+// CanonicalRequest adalah string yang ditandatangani:
+//
+//	METHOD\nREQUEST_URI\nTIMESTAMP\nhex(sha256(body))\nUSER_ID\nUSER_ROLE
+//
+// REQUEST_URI termasuk query string (misal /internal/courses/x/access?authId=y).
+func CanonicalRequest(method, requestURI, timestamp string, body []byte, userID, userRole string) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+		userID,
+		userRole,
+	}, "\n")
+}
+
+// Sign menghitung signature hex untuk canonical request
+func Sign(secret []byte, canonical string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaders membuat header yang harus dikirim pemanggil
+// (dipakai service Go lain / tooling; BFF mengimplementasikan hal yang sama)
+func SignatureHeaders(keyID string, secret []byte, method, requestURI string, body []byte, userID, userRole string, now time.Time) map[string]string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	canonical := CanonicalRequest(method, requestURI, timestamp, body, userID, userRole)
+	return map[string]string{
+		HeaderSignatureKeyID:     keyID,
+		HeaderSignatureTimestamp: timestamp,
+		HeaderSignature:          Sign(secret, canonical),
+	}
+}
+
+// verifySignature mengecek signature secara constant-time
+func verifySignature(secret []byte, canonical, signature string) bool {
+	expected, err := hex.DecodeString(Sign(secret, canonical))
+	if err != nil {
+		return false
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, given)
+}
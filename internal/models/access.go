@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// AccessReason menjelaskan kenapa akses ke lesson diberikan/ditolak
+type AccessReason string
+
+const (
+	AccessOwner              AccessReason = "OWNER"       // Teacher pemilik course
+	AccessEnrolled           AccessReason = "ENROLLED"    // Punya enrollment aktif
+	AccessFreeCourse         AccessReason = "FREE_COURSE" // Course gratis (sudah publish)
+	AccessPreview            AccessReason = "PREVIEW"     // Lesson preview (sudah publish)
+	AccessNotEnrolled        AccessReason = "NOT_ENROLLED"
+	AccessEnrollmentExpired  AccessReason = "ENROLLMENT_EXPIRED"
+	AccessEnrollmentRevoked  AccessReason = "ENROLLMENT_REVOKED"
+	AccessCourseNotPublished AccessReason = "COURSE_NOT_PUBLISHED"
+)
+
+// LessonAccess adalah hasil keputusan akses
+type LessonAccess struct {
+	Allowed bool         `json:"allowed"`
+	Reason  AccessReason `json:"reason"`
+}
+
+// ResolveLessonAccess memutuskan apakah user boleh menonton lesson.
+// 'lesson' boleh nil (cek akses level course), 'enrollment' boleh nil
+// (tidak pernah enroll). Urutan aturan:
+//  1. Pemilik course selalu boleh (status apa pun)
+//  2. Enrollment aktif selalu boleh, termasuk jika course sudah di-unpublish/arsip
+//  3. Selain itu course harus PUBLISHED
+//  4. Course gratis, atau lesson preview, boleh
+func ResolveLessonAccess(course *Course, lesson *Lesson, isOwner bool, enrollment *Enrollment, now time.Time) LessonAccess {
+	if isOwner {
+		return LessonAccess{Allowed: true, Reason: AccessOwner}
+	}
+	if enrollment != nil && enrollment.IsActive(now) {
+		return LessonAccess{Allowed: true, Reason: AccessEnrolled}
+	}
+	if course.Status != StatusPublished {
+		return LessonAccess{Allowed: false, Reason: AccessCourseNotPublished}
+	}
+	if course.IsFree {
+		return LessonAccess{Allowed: true, Reason: AccessFreeCourse}
+	}
+	if lesson != nil && lesson.IsPreview {
+		return LessonAccess{Allowed: true, Reason: AccessPreview}
+	}
+
+	switch {
+	case enrollment == nil:
+		return LessonAccess{Allowed: false, Reason: AccessNotEnrolled}
+	case enrollment.RevokedAt != nil:
+		return LessonAccess{Allowed: false, Reason: AccessEnrollmentRevoked}
+	default:
+		return LessonAccess{Allowed: false, Reason: AccessEnrollmentExpired}
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveLessonAccess(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	published := &Course{Status: StatusPublished}
+	publishedFree := &Course{Status: StatusPublished, IsFree: true}
+	draft := &Course{Status: StatusDraft}
+	unpublished := &Course{Status: StatusUnpublished}
+
+	preview := &Lesson{IsPreview: true}
+	regular := &Lesson{}
+
+	active := &Enrollment{}
+	activeUntil := &Enrollment{ExpiresAt: &future}
+	expired := &Enrollment{ExpiresAt: &past}
+	expiresNow := &Enrollment{ExpiresAt: &now}
+	revoked := &Enrollment{RevokedAt: &past}
+
+	tests := []struct {
+		name        string
+		course      *Course
+		lesson      *Lesson
+		isOwner     bool
+		enrollment  *Enrollment
+		wantAllowed bool
+		wantReason  AccessReason
+	}{
+		// Pemilik
+		{name: "owner on draft", course: draft, lesson: regular, isOwner: true, wantAllowed: true, wantReason: AccessOwner},
+		{name: "owner wins over revoked enrollment", course: published, lesson: regular, isOwner: true, enrollment: revoked, wantAllowed: true, wantReason: AccessOwner},
+
+		// Enrollment
+		{name: "enrolled forever", course: published, lesson: regular, enrollment: active, wantAllowed: true, wantReason: AccessEnrolled},
+		{name: "enrolled until future", course: published, lesson: regular, enrollment: activeUntil, wantAllowed: true, wantReason: AccessEnrolled},
+		{name: "enrolled on unpublished course", course: unpublished, lesson: regular, enrollment: active, wantAllowed: true, wantReason: AccessEnrolled},
+		{name: "enrolled at course level", course: published, lesson: nil, enrollment: active, wantAllowed: true, wantReason: AccessEnrolled},
+		{name: "expired", course: published, lesson: regular, enrollment: expired, wantAllowed: false, wantReason: AccessEnrollmentExpired},
+		{name: "expires exactly now", course: published, lesson: regular, enrollment: expiresNow, wantAllowed: false, wantReason: AccessEnrollmentExpired},
+		{name: "revoked", course: published, lesson: regular, enrollment: revoked, wantAllowed: false, wantReason: AccessEnrollmentRevoked},
+		{name: "not enrolled", course: published, lesson: regular, wantAllowed: false, wantReason: AccessNotEnrolled},
+		{name: "not enrolled at course level", course: published, lesson: nil, wantAllowed: false, wantReason: AccessNotEnrolled},
+
+		// Preview & course gratis
+		{name: "preview lesson", course: published, lesson: preview, wantAllowed: true, wantReason: AccessPreview},
+		{name: "preview with expired enrollment", course: published, lesson: preview, enrollment: expired, wantAllowed: true, wantReason: AccessPreview},
+		{name: "preview on draft", course: draft, lesson: preview, wantAllowed: false, wantReason: AccessCourseNotPublished},
+		{name: "free course", course: publishedFree, lesson: regular, wantAllowed: true, wantReason: AccessFreeCourse},
+		{name: "expired on unpublished course", course: unpublished, lesson: regular, enrollment: expired, wantAllowed: false, wantReason: AccessCourseNotPublished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveLessonAccess(tt.course, tt.lesson, tt.isOwner, tt.enrollment, now)
+			if got.Allowed != tt.wantAllowed || got.Reason != tt.wantReason {
+				t.Fatalf("ResolveLessonAccess = %+v, want {Allowed:%v Reason:%s}", got, tt.wantAllowed, tt.wantReason)
+			}
+		})
+	}
+}
@@ -51,6 +51,7 @@ type Course struct {
 	License     CourseLicense   `gorm:"type:varchar(10);default:'NT'" json:"license"`
 	CreatedAt   time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt   time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+	Version     int64           `gorm:"not null;default:1" json:"version"` // Naik setiap update (ETag)
 
 	// Relasi (GORM akan menanganinya)
 	Teacher   Teacher     `json:"teacher,omitempty"`
@@ -85,6 +86,7 @@ type Chapter struct {
 	Order     int       `gorm:"not null" json:"order"`
 	CourseID  uuid.UUID `gorm:"type:uuid;not null" json:"courseId"`
 	Slug      string    `gorm:"unique;not null" json:"slug"`
+	Version   int64     `gorm:"not null;default:1" json:"version"` // Naik setiap update (ETag)
 	Lessons   []Lesson  `json:"lessons,omitempty"`
 }
 
@@ -97,6 +99,10 @@ type Lesson struct {
 	Duration    int       `json:"duration,omitempty"` // durasi dalam detik
 	PlaybackID  string    `gorm:"not null" json:"playbackId"` // ID dari Cloudflare Stream
 	IsPreview   bool      `gorm:"default:false" json:"isPreview"`
+	Version     int64     `gorm:"not null;default:1" json:"version"` // Naik setiap update (ETag)
+
+	// CourseID hanya dibaca (diisi via JOIN ke 'chapters'), tidak disimpan
+	CourseID    uuid.UUID `gorm:"->;-:migration" json:"courseId,omitempty"`
 }
 
 // Category memetakan tabel 'categories'
@@ -141,6 +147,31 @@ type Coupon struct {
 	Categories    []Category   `gorm:"many2many:coupon_categories;" json:"-"`
 }
 
+// CouponRedemption memetakan tabel 'coupon_redemptions'
+// Satu baris per pemakaian kupon; ReleasedAt diisi jika pembayaran gagal
+// sehingga kuota kupon dikembalikan tepat satu kali.
+type CouponRedemption struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CouponID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"couponId"`
+	CourseID   uuid.UUID  `gorm:"type:uuid;not null" json:"courseId"`
+	AuthID     string     `json:"authId,omitempty"` // Pembeli (dari gateway)
+	RedeemedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"redeemedAt"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+// CourseStatusEvent memetakan tabel 'course_status_events'
+// Tabel ini append-only: setiap perubahan status menambah satu baris,
+// tidak pernah di-update atau dihapus.
+type CourseStatusEvent struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CourseID    uuid.UUID    `gorm:"type:uuid;not null;index" json:"courseId"`
+	FromStatus  CourseStatus `gorm:"type:varchar(50);not null" json:"fromStatus"`
+	ToStatus    CourseStatus `gorm:"type:varchar(50);not null" json:"toStatus"`
+	ActorAuthID string       `gorm:"not null" json:"actorAuthId"`
+	Reason      string       `json:"reason,omitempty"` // Catatan reviewer / alasan perubahan
+	CreatedAt   time.Time    `gorm:"default:CURRENT_TIMESTAMP;index" json:"createdAt"`
+}
+
 // Fungsi hook GORM untuk UUID
 func (m *Course) BeforeCreate(tx *gorm.DB) (err error) {
 	if m.ID == uuid.Nil {
@@ -184,4 +215,16 @@ func (m *Category) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+func (m *CouponRedemption) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
+func (m *CourseStatusEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
 // ... (tambahkan hook serupa untuk Chapter, Lesson, Category, Tag, Sale, Coupon) ...
\ No newline at end of file
@@ -0,0 +1,229 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	RoleTeacher Role = "TEACHER"
+	RoleCurator Role = "CURATOR"
+	RoleAdmin   Role = "ADMIN"
+)
+
+// AllCourseStatuses berisi semua status yang dikenal service ini
+var AllCourseStatuses = []CourseStatus{
+	StatusDraft,
+	StatusIncomplete,
+	StatusPending,
+	StatusFollowedUp,
+	StatusApproved,
+	StatusPublished,
+	StatusRejected,
+	StatusUnpublished,
+	StatusArchived,
+}
+
+// IsValid mengecek apakah status termasuk salah satu konstanta di atas
+func (s CourseStatus) IsValid() bool {
+	for _, known := range AllCourseStatuses {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultStatusTransitions adalah tabel transisi bawaan:
+// status asal -> status tujuan -> role yang boleh melakukannya
+var defaultStatusTransitions = map[CourseStatus]map[CourseStatus][]Role{
+	StatusDraft: {
+		StatusIncomplete: {RoleTeacher, RoleAdmin}, // Otomatis saat pengajuan gagal cek readiness
+		StatusPending:    {RoleTeacher, RoleAdmin},
+		StatusArchived:   {RoleTeacher, RoleAdmin},
+	},
+	StatusIncomplete: {
+		StatusDraft:    {RoleTeacher, RoleAdmin},
+		StatusPending:  {RoleTeacher, RoleAdmin},
+		StatusArchived: {RoleTeacher, RoleAdmin},
+	},
+	StatusPending: {
+		StatusFollowedUp: {RoleCurator, RoleAdmin},
+		StatusApproved:   {RoleCurator, RoleAdmin},
+		StatusRejected:   {RoleCurator, RoleAdmin},
+		StatusDraft:      {RoleTeacher, RoleAdmin}, // Teacher menarik kembali pengajuan
+	},
+	StatusFollowedUp: {
+		StatusIncomplete: {RoleTeacher, RoleAdmin},
+		StatusPending:    {RoleTeacher, RoleAdmin}, // Diajukan ulang setelah perbaikan
+		StatusDraft:      {RoleTeacher, RoleAdmin},
+	},
+	StatusRejected: {
+		StatusDraft:    {RoleTeacher, RoleAdmin},
+		StatusArchived: {RoleTeacher, RoleAdmin},
+	},
+	StatusApproved: {
+		StatusPublished: {RoleTeacher, RoleCurator, RoleAdmin},
+		StatusDraft:     {RoleTeacher, RoleAdmin},
+	},
+	StatusPublished: {
+		StatusUnpublished: {RoleTeacher, RoleCurator, RoleAdmin},
+		StatusArchived:    {RoleAdmin},
+	},
+	StatusUnpublished: {
+		StatusPublished: {RoleTeacher, RoleCurator, RoleAdmin},
+		StatusDraft:     {RoleTeacher, RoleAdmin},
+		StatusArchived:  {RoleTeacher, RoleAdmin},
+	},
+	StatusArchived: {
+		StatusDraft: {RoleAdmin}, // Pemulihan hanya oleh admin
+	},
+}
+
+// StatusTransitionError dikembalikan jika transisi tidak ada di tabel
+type StatusTransitionError struct {
+	From    CourseStatus
+	To      CourseStatus
+	Allowed []CourseStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("invalid status transition from %s to %s", e.From, e.To)
+}
+
+// StatusRoleError dikembalikan jika transisi valid tapi role tidak diizinkan
+type StatusRoleError struct {
+	From  CourseStatus
+	To    CourseStatus
+	Role  Role
+	Roles []Role
+}
+
+func (e *StatusRoleError) Error() string {
+	return fmt.Sprintf("role %s may not change status from %s to %s", e.Role, e.From, e.To)
+}
+
+// StatusPolicy memegang tabel transisi status beserta role yang diizinkan
+type StatusPolicy struct {
+	transitions map[CourseStatus]map[CourseStatus][]Role
+}
+
+// NewStatusPolicy membuat policy dari tabel bawaan, lalu menimpa role
+// untuk transisi yang disebut di 'overrides'.
+// Format: "FROM>TO=ROLE|ROLE,FROM>TO=ROLE"
+// Contoh: "APPROVED>PUBLISHED=CURATOR|ADMIN,PUBLISHED>ARCHIVED=ADMIN"
+func NewStatusPolicy(overrides string) (*StatusPolicy, error) {
+	// 1. Salin tabel bawaan agar tidak termutasi
+	transitions := make(map[CourseStatus]map[CourseStatus][]Role, len(defaultStatusTransitions))
+	for from, targets := range defaultStatusTransitions {
+		transitions[from] = make(map[CourseStatus][]Role, len(targets))
+		for to, roles := range targets {
+			transitions[from][to] = append([]Role(nil), roles...)
+		}
+	}
+
+	// 2. Terapkan override dari konfigurasi
+	for _, entry := range strings.Split(overrides, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pair, roleList, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid status policy entry %q: expected FROM>TO=ROLES", entry)
+		}
+		fromStr, toStr, ok := strings.Cut(pair, ">")
+		if !ok {
+			return nil, fmt.Errorf("invalid status policy entry %q: expected FROM>TO=ROLES", entry)
+		}
+
+		from := CourseStatus(strings.ToUpper(strings.TrimSpace(fromStr)))
+		to := CourseStatus(strings.ToUpper(strings.TrimSpace(toStr)))
+		if _, exists := transitions[from][to]; !exists {
+			// Override hanya boleh mengatur role, bukan menambah transisi baru
+			return nil, fmt.Errorf("invalid status policy entry %q: unknown transition %s>%s", entry, from, to)
+		}
+
+		var roles []Role
+		for _, r := range strings.Split(roleList, "|") {
+			role := Role(strings.ToUpper(strings.TrimSpace(r)))
+			if role == "" {
+				continue
+			}
+			// Typo nama role (misal CURRATOR) harus gagal saat startup,
+			// bukan diam-diam mengunci transisi
+			if !role.IsValid() {
+				return nil, fmt.Errorf("invalid status policy entry %q: unknown role %s", entry, role)
+			}
+			roles = append(roles, role)
+		}
+		transitions[from][to] = roles
+	}
+
+	return &StatusPolicy{transitions: transitions}, nil
+}
+
+// AllowedNext mengembalikan status tujuan yang sah dari status 'from'
+// (urutan mengikuti AllCourseStatuses agar respons stabil)
+func (p *StatusPolicy) AllowedNext(from CourseStatus) []CourseStatus {
+	allowed := []CourseStatus{}
+	for _, to := range AllCourseStatuses {
+		if _, ok := p.transitions[from][to]; ok {
+			allowed = append(allowed, to)
+		}
+	}
+	return allowed
+}
+
+// Check memvalidasi transisi 'from' -> 'to' untuk 'role'
+func (p *StatusPolicy) Check(from, to CourseStatus, role Role) error {
+	roles, ok := p.transitions[from][to]
+	if !ok {
+		return &StatusTransitionError{From: from, To: to, Allowed: p.AllowedNext(from)}
+	}
+
+	for _, r := range roles {
+		if r == role {
+			return nil
+		}
+	}
+	return &StatusRoleError{From: from, To: to, Role: role, Roles: roles}
+}
+
+// CheckAny memvalidasi transisi untuk user dengan beberapa role:
+// cukup salah satu role yang diizinkan
+func (p *StatusPolicy) CheckAny(from, to CourseStatus, roles []Role) error {
+	if len(roles) == 0 {
+		return p.Check(from, to, "")
+	}
+
+	var firstErr error
+	for _, role := range roles {
+		err := p.Check(from, to, role)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CheckActor memvalidasi transisi untuk user dengan beberapa role terhadap
+// course tertentu. Role global saja tidak cukup:
+//   - TEACHER hanya berlaku pada course miliknya sendiri (teacher yang juga
+//     curator tidak boleh menarik/mengarsipkan course teacher lain)
+//   - CURATOR tidak berlaku pada course miliknya sendiri (tidak boleh
+//     me-review pengajuannya sendiri)
+func (p *StatusPolicy) CheckActor(from, to CourseStatus, roles []Role, isOwner bool) error {
+	effective := make([]Role, 0, len(roles))
+	for _, role := range roles {
+		if (role == RoleTeacher && !isOwner) || (role == RoleCurator && isOwner) {
+			continue
+		}
+		effective = append(effective, role)
+	}
+	return p.CheckAny(from, to, effective)
+}
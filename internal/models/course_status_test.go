@@ -0,0 +1,180 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewStatusPolicyOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides string
+		wantErr   string // Potongan pesan error; "" = harus berhasil
+	}{
+		{name: "empty", overrides: ""},
+		{name: "blank entries ignored", overrides: " , ,"},
+		{name: "single override", overrides: "APPROVED>PUBLISHED=CURATOR|ADMIN"},
+		{name: "lowercase and spaces", overrides: " approved > published = curator | admin "},
+		{name: "multiple overrides", overrides: "APPROVED>PUBLISHED=ADMIN,PUBLISHED>ARCHIVED=ADMIN|CURATOR"},
+		{name: "missing roles separator", overrides: "APPROVED>PUBLISHED", wantErr: "expected FROM>TO=ROLES"},
+		{name: "missing transition separator", overrides: "APPROVED=ADMIN", wantErr: "expected FROM>TO=ROLES"},
+		{name: "unknown transition", overrides: "DRAFT>PUBLISHED=ADMIN", wantErr: "unknown transition DRAFT>PUBLISHED"},
+		{name: "unknown status", overrides: "DRAFTT>PENDING_REVIEW=ADMIN", wantErr: "unknown transition"},
+		{name: "unknown role", overrides: "APPROVED>PUBLISHED=CURRATOR", wantErr: "unknown role CURRATOR"},
+		{name: "unknown role among valid ones", overrides: "APPROVED>PUBLISHED=ADMIN|TEACHR", wantErr: "unknown role TEACHR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewStatusPolicy(tt.overrides)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewStatusPolicy(%q) error = %v", tt.overrides, err)
+				}
+				if policy == nil {
+					t.Fatal("NewStatusPolicy returned nil policy")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewStatusPolicy(%q) error = %v, want containing %q", tt.overrides, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewStatusPolicyDoesNotMutateDefaults(t *testing.T) {
+	if _, err := NewStatusPolicy("PUBLISHED>ARCHIVED=TEACHER"); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewStatusPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Check(StatusPublished, StatusArchived, RoleTeacher); err == nil {
+		t.Fatal("override leaked into the default transition table")
+	}
+}
+
+func TestStatusPolicyCheck(t *testing.T) {
+	policy, err := NewStatusPolicy("APPROVED>PUBLISHED=CURATOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		from, to  CourseStatus
+		role      Role
+		wantError any // nil, *StatusTransitionError atau *StatusRoleError
+	}{
+		{name: "allowed", from: StatusDraft, to: StatusPending, role: RoleTeacher},
+		{name: "unknown transition", from: StatusDraft, to: StatusPublished, role: RoleAdmin, wantError: &StatusTransitionError{}},
+		{name: "same status", from: StatusDraft, to: StatusDraft, role: RoleAdmin, wantError: &StatusTransitionError{}},
+		{name: "role not allowed", from: StatusPending, to: StatusApproved, role: RoleTeacher, wantError: &StatusRoleError{}},
+		{name: "override grants role", from: StatusApproved, to: StatusPublished, role: RoleCurator},
+		{name: "override removes role", from: StatusApproved, to: StatusPublished, role: RoleTeacher, wantError: &StatusRoleError{}},
+		{name: "override removes admin", from: StatusApproved, to: StatusPublished, role: RoleAdmin, wantError: &StatusRoleError{}},
+		{name: "empty role", from: StatusDraft, to: StatusPending, role: "", wantError: &StatusRoleError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertStatusError(t, policy.Check(tt.from, tt.to, tt.role), tt.wantError)
+		})
+	}
+}
+
+func TestStatusTransitionErrorListsAllowedTargets(t *testing.T) {
+	policy, err := NewStatusPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var transitionErr *StatusTransitionError
+	if !errors.As(policy.Check(StatusArchived, StatusPublished, RoleAdmin), &transitionErr) {
+		t.Fatal("expected *StatusTransitionError")
+	}
+	if len(transitionErr.Allowed) != 1 || transitionErr.Allowed[0] != StatusDraft {
+		t.Fatalf("Allowed = %v, want [%s]", transitionErr.Allowed, StatusDraft)
+	}
+}
+
+func TestStatusPolicyCheckAny(t *testing.T) {
+	policy, err := NewStatusPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		from, to  CourseStatus
+		roles     []Role
+		wantError any
+	}{
+		{name: "no roles", from: StatusDraft, to: StatusPending, roles: nil, wantError: &StatusRoleError{}},
+		{name: "single allowed role", from: StatusDraft, to: StatusPending, roles: []Role{RoleTeacher}},
+		{name: "second role allowed", from: StatusPending, to: StatusApproved, roles: []Role{RoleTeacher, RoleCurator}},
+		{name: "no role allowed", from: StatusPublished, to: StatusArchived, roles: []Role{RoleTeacher, RoleCurator}, wantError: &StatusRoleError{}},
+		{name: "unknown transition wins over roles", from: StatusDraft, to: StatusPublished, roles: []Role{RoleTeacher, RoleAdmin}, wantError: &StatusTransitionError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertStatusError(t, policy.CheckAny(tt.from, tt.to, tt.roles), tt.wantError)
+		})
+	}
+}
+
+func TestStatusPolicyCheckActor(t *testing.T) {
+	policy, err := NewStatusPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	teacherCurator := []Role{RoleTeacher, RoleCurator}
+
+	tests := []struct {
+		name      string
+		from, to  CourseStatus
+		roles     []Role
+		isOwner   bool
+		wantError any
+	}{
+		{name: "owner withdraws submission", from: StatusPending, to: StatusDraft, roles: []Role{RoleTeacher}, isOwner: true},
+		{name: "teacher on another teacher's course", from: StatusDraft, to: StatusPending, roles: []Role{RoleTeacher}, wantError: &StatusRoleError{}},
+		{name: "teacher-curator withdraws another teacher's submission", from: StatusPending, to: StatusDraft, roles: teacherCurator, wantError: &StatusRoleError{}},
+		{name: "teacher-curator archives another teacher's course", from: StatusDraft, to: StatusArchived, roles: teacherCurator, wantError: &StatusRoleError{}},
+		{name: "teacher-curator reviews another teacher's course", from: StatusPending, to: StatusApproved, roles: teacherCurator},
+		{name: "teacher-curator approves own submission", from: StatusPending, to: StatusApproved, roles: teacherCurator, isOwner: true, wantError: &StatusRoleError{}},
+		{name: "curator rejects own submission", from: StatusPending, to: StatusRejected, roles: []Role{RoleCurator}, isOwner: true, wantError: &StatusRoleError{}},
+		{name: "teacher-curator withdraws own submission", from: StatusPending, to: StatusDraft, roles: teacherCurator, isOwner: true},
+		{name: "admin ignores ownership", from: StatusPending, to: StatusApproved, roles: []Role{RoleAdmin}, isOwner: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertStatusError(t, policy.CheckActor(tt.from, tt.to, tt.roles, tt.isOwner), tt.wantError)
+		})
+	}
+}
+
+func assertStatusError(t *testing.T, err error, want any) {
+	t.Helper()
+	switch want.(type) {
+	case nil:
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+	case *StatusTransitionError:
+		var target *StatusTransitionError
+		if !errors.As(err, &target) {
+			t.Fatalf("error = %v, want *StatusTransitionError", err)
+		}
+	case *StatusRoleError:
+		var target *StatusRoleError
+		if !errors.As(err, &target) {
+			t.Fatalf("error = %v, want *StatusRoleError", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EnrollmentSource adalah asal hak akses student ke sebuah course
+type EnrollmentSource string
+
+const (
+	EnrollmentPurchase   EnrollmentSource = "PURCHASE"    // Dibeli (Payment-service)
+	EnrollmentFree       EnrollmentSource = "FREE"        // Course gratis
+	EnrollmentCoupon     EnrollmentSource = "COUPON"      // Kupon 100%
+	EnrollmentAdminGrant EnrollmentSource = "ADMIN_GRANT" // Diberikan manual oleh admin
+)
+
+// IsValid mengecek apakah source dikenal
+func (s EnrollmentSource) IsValid() bool {
+	switch s {
+	case EnrollmentPurchase, EnrollmentFree, EnrollmentCoupon, EnrollmentAdminGrant:
+		return true
+	}
+	return false
+}
+
+// Enrollment memetakan tabel 'enrollments'
+// Baris tidak dihapus saat dicabut; RevokedAt diisi agar riwayat tetap ada.
+// Hanya boleh ada satu enrollment yang belum dicabut per student per course.
+type Enrollment struct {
+	ID           uuid.UUID        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	StudentID    uuid.UUID        `gorm:"type:uuid;not null" json:"studentId"`
+	CourseID     uuid.UUID        `gorm:"type:uuid;not null;index" json:"courseId"`
+	Source       EnrollmentSource `gorm:"type:varchar(20);not null" json:"source"`
+	Reference    string           `json:"reference,omitempty"` // Misal ID order dari Payment-service
+	GrantedAt    time.Time        `gorm:"default:CURRENT_TIMESTAMP" json:"grantedAt"`
+	ExpiresAt    *time.Time       `json:"expiresAt,omitempty"` // nil = selamanya
+	RevokedAt    *time.Time       `json:"revokedAt,omitempty"`
+	RevokeReason string           `json:"revokeReason,omitempty"`
+}
+
+// IsActive: belum dicabut dan belum kedaluwarsa pada waktu 'now'
+func (e *Enrollment) IsActive(now time.Time) bool {
+	if e.RevokedAt != nil {
+		return false
+	}
+	return e.ExpiresAt == nil || now.Before(*e.ExpiresAt)
+}
+
+func (m *Student) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
+func (m *Enrollment) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Versi envelope event. Naikkan jika struktur envelope berubah
+// secara tidak kompatibel (consumer memakai field 'version').
+const EventEnvelopeVersion = 1
+
+// Tipe event domain course
+const (
+	EventCourseCreated           = "course.created"
+	EventCourseStatusChanged     = "course.status_changed"
+	EventCoursePublished         = "course.published"
+	EventCourseRepriced          = "course.repriced"
+	EventCourseCurriculumChanged = "course.curriculum_changed"
+	EventEnrollmentGranted       = "enrollment.granted"
+	EventEnrollmentRevoked       = "enrollment.revoked"
+)
+
+// EventEnvelope adalah bentuk JSON yang dikirim ke consumer
+type EventEnvelope struct {
+	ID          uuid.UUID   `json:"id"`
+	Type        string      `json:"type"`
+	Version     int         `json:"version"`
+	Source      string      `json:"source"`
+	AggregateID uuid.UUID   `json:"aggregateId"`
+	OccurredAt  time.Time   `json:"occurredAt"`
+	Data        interface{} `json:"data"`
+}
+
+// OutboxEvent memetakan tabel 'outbox_events'
+// Ditulis di transaksi yang sama dengan mutasi data, lalu dikirim oleh relay.
+type OutboxEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	AggregateID   uuid.UUID  `gorm:"type:uuid;not null" json:"aggregateId"`
+	EventType     string     `gorm:"type:varchar(100);not null" json:"eventType"`
+	Payload       []byte     `gorm:"type:jsonb;not null" json:"payload"` // EventEnvelope (JSON)
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	NextAttemptAt time.Time  `gorm:"default:CURRENT_TIMESTAMP;index" json:"nextAttemptAt"`
+	PublishedAt   *time.Time `gorm:"index" json:"publishedAt,omitempty"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+func (m *OutboxEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
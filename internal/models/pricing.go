@@ -0,0 +1,45 @@
+package models
+
+import "math"
+
+// ApplyDiscount menghitung harga setelah diskon.
+// PERCENTAGE memotong persentase dari harga, FIXED_AMOUNT memotong nominal tetap.
+// Hasil tidak pernah di bawah nol dan dibulatkan ke 2 desimal.
+func ApplyDiscount(price float64, discountType DiscountType, value float64) float64 {
+	final := price
+	switch discountType {
+	case DiscountPercentage:
+		final = price - (price * value / 100)
+	case DiscountFixed:
+		final = price - value
+	}
+
+	if final < 0 {
+		final = 0
+	}
+	return math.Round(final*100) / 100
+}
+
+// FinalPrice menghitung harga course setelah sale ini diterapkan
+func (s *Sale) FinalPrice(price float64) float64 {
+	return ApplyDiscount(price, s.DiscountType, s.DiscountValue)
+}
+
+// BestSale memilih sale yang menghasilkan harga akhir paling rendah.
+// Mengembalikan nil dan harga dasar jika tidak ada sale yang menurunkan harga.
+func BestSale(price float64, sales []*Sale) (*Sale, float64) {
+	var best *Sale
+	finalPrice := price
+	for _, sale := range sales {
+		if p := sale.FinalPrice(price); p < finalPrice {
+			finalPrice = p
+			best = sale
+		}
+	}
+	return best, finalPrice
+}
+
+// FinalPrice menghitung harga setelah kupon ini diterapkan
+func (c *Coupon) FinalPrice(price float64) float64 {
+	return ApplyDiscount(price, c.DiscountType, c.DiscountValue)
+}
@@ -0,0 +1,84 @@
+package models
+
+import "testing"
+
+func TestApplyDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		price        float64
+		discountType DiscountType
+		value        float64
+		want         float64
+	}{
+		{name: "percentage", price: 200000, discountType: DiscountPercentage, value: 25, want: 150000},
+		{name: "fixed", price: 200000, discountType: DiscountFixed, value: 50000, want: 150000},
+		{name: "percentage rounds to 2 decimals", price: 19.99, discountType: DiscountPercentage, value: 15, want: 16.99},
+		{name: "fixed rounds to 2 decimals", price: 10.005, discountType: DiscountFixed, value: 0.001, want: 10},
+		{name: "percentage over 100 clamps to 0", price: 100, discountType: DiscountPercentage, value: 150, want: 0},
+		{name: "fixed over price clamps to 0", price: 100, discountType: DiscountFixed, value: 250, want: 0},
+		{name: "exactly free", price: 100, discountType: DiscountFixed, value: 100, want: 0},
+		{name: "zero discount", price: 100, discountType: DiscountPercentage, value: 0, want: 100},
+		{name: "unknown type keeps price", price: 100, discountType: "BOGUS", value: 50, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyDiscount(tt.price, tt.discountType, tt.value); got != tt.want {
+				t.Fatalf("ApplyDiscount(%v, %s, %v) = %v, want %v", tt.price, tt.discountType, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestSale(t *testing.T) {
+	tenPercent := &Sale{Name: "10%", DiscountType: DiscountPercentage, DiscountValue: 10}
+	fiftyPercent := &Sale{Name: "50%", DiscountType: DiscountPercentage, DiscountValue: 50}
+	fixed30k := &Sale{Name: "30k", DiscountType: DiscountFixed, DiscountValue: 30000}
+	fixed50k := &Sale{Name: "50k", DiscountType: DiscountFixed, DiscountValue: 50000}
+	fixedHuge := &Sale{Name: "huge", DiscountType: DiscountFixed, DiscountValue: 1000000}
+	noop := &Sale{Name: "noop", DiscountType: DiscountPercentage, DiscountValue: 0}
+
+	tests := []struct {
+		name      string
+		price     float64
+		sales     []*Sale
+		wantSale  *Sale
+		wantPrice float64
+	}{
+		{name: "no sales", price: 100000, sales: nil, wantSale: nil, wantPrice: 100000},
+		{name: "single sale", price: 100000, sales: []*Sale{tenPercent}, wantSale: tenPercent, wantPrice: 90000},
+		{name: "overlapping picks lowest price", price: 100000, sales: []*Sale{tenPercent, fixed30k, fiftyPercent}, wantSale: fiftyPercent, wantPrice: 50000},
+		// Persentase vs nominal tetap bergantung pada harga dasar
+		{name: "fixed beats percentage on cheap course", price: 40000, sales: []*Sale{fiftyPercent, fixed30k}, wantSale: fixed30k, wantPrice: 10000},
+		{name: "tie keeps first sale", price: 100000, sales: []*Sale{fiftyPercent, fixed50k}, wantSale: fiftyPercent, wantPrice: 50000},
+		{name: "clamped to zero", price: 100000, sales: []*Sale{tenPercent, fixedHuge}, wantSale: fixedHuge, wantPrice: 0},
+		{name: "sale without effect is ignored", price: 100000, sales: []*Sale{noop}, wantSale: nil, wantPrice: 100000},
+		{name: "free price", price: 0, sales: []*Sale{fiftyPercent}, wantSale: nil, wantPrice: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sale, price := BestSale(tt.price, tt.sales)
+			if sale != tt.wantSale {
+				t.Fatalf("BestSale sale = %v, want %v", saleName(sale), saleName(tt.wantSale))
+			}
+			if price != tt.wantPrice {
+				t.Fatalf("BestSale price = %v, want %v", price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestCouponFinalPrice(t *testing.T) {
+	coupon := &Coupon{Code: "HEMAT", DiscountType: DiscountPercentage, DiscountValue: 20}
+	if got := coupon.FinalPrice(99.99); got != 79.99 {
+		t.Fatalf("FinalPrice = %v, want 79.99", got)
+	}
+}
+
+func saleName(s *Sale) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return s.Name
+}
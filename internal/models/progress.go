@@ -0,0 +1,172 @@
+package models
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CompletionThreshold: lesson dianggap selesai jika ditonton minimal
+// persentase ini (credits/outro biasanya tidak ditonton sampai habis)
+const CompletionThreshold = 90.0
+
+// WatchedPercent menghitung persen ditonton (0-100, 2 desimal) dari laporan
+// player: 'reported' jika dikirim, selain itu posisi / durasi lesson.
+// Lesson yang dilaporkan selesai selalu 100.
+func WatchedPercent(positionSeconds int, reported *float64, completed bool, duration int) float64 {
+	var percent float64
+	switch {
+	case reported != nil:
+		percent = *reported
+	case duration > 0:
+		percent = float64(positionSeconds) / float64(duration) * 100
+	}
+	if completed {
+		percent = 100
+	}
+	percent = math.Min(math.Max(percent, 0), 100)
+	return math.Round(percent*100) / 100
+}
+
+// IsLessonCompleted: player melaporkan selesai, atau sudah ditonton
+// minimal CompletionThreshold
+func IsLessonCompleted(percent float64, completed bool) bool {
+	return completed || percent >= CompletionThreshold
+}
+
+// LessonProgress memetakan tabel 'lesson_progress'
+// Satu baris per student per lesson, di-upsert berkala oleh player.
+type LessonProgress struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	StudentID       uuid.UUID  `gorm:"type:uuid;not null" json:"studentId"`
+	LessonID        uuid.UUID  `gorm:"type:uuid;not null" json:"lessonId"`
+	CourseID        uuid.UUID  `gorm:"type:uuid;not null" json:"courseId"` // Denormalisasi untuk ringkasan
+	PositionSeconds int        `gorm:"not null;default:0" json:"positionSeconds"`
+	PercentWatched  float64    `gorm:"type:numeric(5,2);not null;default:0" json:"percentWatched"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+	UpdatedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+func (LessonProgress) TableName() string {
+	return "lesson_progress"
+}
+
+func (m *LessonProgress) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
+
+// ResumePoint adalah lesson berikutnya yang harus diputar
+type ResumePoint struct {
+	LessonID        uuid.UUID `json:"lessonId"`
+	ChapterID       uuid.UUID `json:"chapterId"`
+	Title           string    `json:"title"`
+	PositionSeconds int       `json:"positionSeconds"`
+}
+
+// CourseProgressSummary adalah ringkasan progress student di satu course
+type CourseProgressSummary struct {
+	CourseID         uuid.UUID         `json:"courseId"`
+	TotalLessons     int               `json:"totalLessons"`
+	CompletedLessons int               `json:"completedLessons"`
+	TotalSeconds     int               `json:"totalSeconds"`
+	WatchedSeconds   int               `json:"watchedSeconds"`
+	PercentComplete  float64           `json:"percentComplete"`
+	NextLesson       *ResumePoint      `json:"nextLesson"` // nil jika semua selesai
+	Lessons          []*LessonProgress `json:"lessons"`
+}
+
+// SummarizeCourseProgress menghitung ringkasan dari kurikulum dan baris
+// progress milik student. Kurikulum diurutkan di sini berdasarkan Chapter.Order
+// lalu Lesson.Order (urutan 'chapters' dari pemanggil tidak berpengaruh).
+//
+// Persentase dibobot dengan Lesson.Duration. Jika tidak ada lesson yang punya
+// durasi, persentase dihitung dari jumlah lesson yang selesai.
+//
+// Lesson berikutnya: lesson terakhir yang ditonton jika belum selesai; jika sudah,
+// lesson belum selesai pertama setelahnya; jika tidak ada, yang pertama dari awal.
+func SummarizeCourseProgress(courseID uuid.UUID, chapters []Chapter, progress []*LessonProgress) CourseProgressSummary {
+	summary := CourseProgressSummary{CourseID: courseID, Lessons: progress}
+
+	byLesson := make(map[uuid.UUID]*LessonProgress, len(progress))
+	for _, p := range progress {
+		byLesson[p.LessonID] = p
+	}
+
+	// 1. Ratakan kurikulum sesuai urutan (salinan; input tidak diubah)
+	chapters = slices.Clone(chapters)
+	slices.SortStableFunc(chapters, func(a, b Chapter) int { return cmp.Compare(a.Order, b.Order) })
+	var lessons []Lesson
+	for _, chapter := range chapters {
+		chapterLessons := slices.Clone(chapter.Lessons)
+		slices.SortStableFunc(chapterLessons, func(a, b Lesson) int { return cmp.Compare(a.Order, b.Order) })
+		lessons = append(lessons, chapterLessons...)
+	}
+	summary.TotalLessons = len(lessons)
+
+	// 2. Hitung total & yang sudah ditonton; cari lesson terakhir yang diputar
+	lastIndex := -1
+	var lastUpdated time.Time
+	for i, lesson := range lessons {
+		summary.TotalSeconds += lesson.Duration
+
+		p, ok := byLesson[lesson.ID]
+		if !ok {
+			continue
+		}
+		if p.CompletedAt != nil {
+			summary.CompletedLessons++
+			summary.WatchedSeconds += lesson.Duration
+		} else {
+			summary.WatchedSeconds += int(math.Round(float64(lesson.Duration) * p.PercentWatched / 100))
+		}
+		if p.UpdatedAt.After(lastUpdated) {
+			lastUpdated = p.UpdatedAt
+			lastIndex = i
+		}
+	}
+
+	switch {
+	case summary.TotalSeconds > 0:
+		summary.PercentComplete = float64(summary.WatchedSeconds) / float64(summary.TotalSeconds) * 100
+	case summary.TotalLessons > 0:
+		summary.PercentComplete = float64(summary.CompletedLessons) / float64(summary.TotalLessons) * 100
+	}
+	summary.PercentComplete = math.Round(summary.PercentComplete*100) / 100
+
+	// 3. Tentukan lesson berikutnya
+	isDone := func(i int) bool {
+		p, ok := byLesson[lessons[i].ID]
+		return ok && p.CompletedAt != nil
+	}
+	next := -1
+	if lastIndex >= 0 && !isDone(lastIndex) {
+		next = lastIndex
+	}
+	for i := lastIndex + 1; next < 0 && i < len(lessons); i++ {
+		if !isDone(i) {
+			next = i
+		}
+	}
+	for i := 0; next < 0 && i < len(lessons); i++ {
+		if !isDone(i) {
+			next = i
+		}
+	}
+
+	if next >= 0 {
+		lesson := lessons[next]
+		summary.NextLesson = &ResumePoint{LessonID: lesson.ID, ChapterID: lesson.ChapterID, Title: lesson.Title}
+		if p, ok := byLesson[lesson.ID]; ok {
+			summary.NextLesson.PositionSeconds = p.PositionSeconds
+		}
+	}
+
+	return summary
+}
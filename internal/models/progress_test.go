@@ -0,0 +1,187 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// curriculum membuat chapter (Order 1..n) berisi lesson (Order 1..n) dengan
+// durasi 'durations[chapter][lesson]' detik
+func curriculum(durations ...[]int) []Chapter {
+	chapters := make([]Chapter, len(durations))
+	for i, lessons := range durations {
+		chapters[i] = Chapter{ID: uuid.New(), Order: i + 1}
+		for j, duration := range lessons {
+			chapters[i].Lessons = append(chapters[i].Lessons, Lesson{
+				ID:        uuid.New(),
+				ChapterID: chapters[i].ID,
+				Title:     "Lesson",
+				Order:     j + 1,
+				Duration:  duration,
+			})
+		}
+	}
+	return chapters
+}
+
+// watched membuat baris progress; 'completed' mengisi CompletedAt
+func watched(lesson Lesson, percent float64, position int, at time.Time, completed bool) *LessonProgress {
+	p := &LessonProgress{LessonID: lesson.ID, PercentWatched: percent, PositionSeconds: position, UpdatedAt: at}
+	if completed {
+		p.CompletedAt = &at
+	}
+	return p
+}
+
+func TestSummarizeCourseProgress(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	t1, t2 := t0.Add(time.Minute), t0.Add(2*time.Minute)
+
+	weighted := curriculum([]int{100, 300})
+	partial := curriculum([]int{200, 200})
+	zeroDuration := curriculum([]int{0, 0}, []int{0, 0})
+	finished := curriculum([]int{60}, []int{60})
+	wrapAround := curriculum([]int{60, 60, 60})
+
+	// Chapter & lesson sengaja tidak urut: resume mengikuti Order, bukan posisi di slice
+	shuffled := curriculum([]int{60, 60}, []int{60})
+	shuffled[0], shuffled[1] = shuffled[1], shuffled[0]
+	first := shuffled[1].Lessons[0]
+	second := shuffled[1].Lessons[1]
+	shuffled[1].Lessons[0], shuffled[1].Lessons[1] = second, first
+
+	tests := []struct {
+		name            string
+		chapters        []Chapter
+		progress        []*LessonProgress
+		wantTotal       int
+		wantCompleted   int
+		wantTotalSecs   int
+		wantWatchedSecs int
+		wantPercent     float64
+		wantNext        *Lesson // nil = semua selesai / kurikulum kosong
+		wantPosition    int
+	}{
+		{name: "empty curriculum"},
+		{
+			name: "no progress starts at the first lesson", chapters: weighted,
+			wantTotal: 2, wantTotalSecs: 400, wantNext: &weighted[0].Lessons[0],
+		},
+		{
+			name: "percent is weighted by duration", chapters: weighted,
+			progress:  []*LessonProgress{watched(weighted[0].Lessons[0], 100, 100, t0, true)},
+			wantTotal: 2, wantCompleted: 1, wantTotalSecs: 400, wantWatchedSecs: 100, wantPercent: 25,
+			wantNext: &weighted[0].Lessons[1],
+		},
+		{
+			name: "unfinished lesson counts its watched share and resumes at its position", chapters: partial,
+			progress:  []*LessonProgress{watched(partial[0].Lessons[0], 50, 100, t0, false)},
+			wantTotal: 2, wantTotalSecs: 400, wantWatchedSecs: 100, wantPercent: 25,
+			wantNext: &partial[0].Lessons[0], wantPosition: 100,
+		},
+		{
+			name: "zero durations fall back to lesson count", chapters: zeroDuration,
+			progress:  []*LessonProgress{watched(zeroDuration[0].Lessons[0], 100, 0, t0, true)},
+			wantTotal: 4, wantCompleted: 1, wantPercent: 25,
+			wantNext: &zeroDuration[0].Lessons[1],
+		},
+		{
+			name: "fully completed course has no next lesson", chapters: finished,
+			progress: []*LessonProgress{
+				watched(finished[0].Lessons[0], 100, 60, t0, true),
+				watched(finished[1].Lessons[0], 100, 60, t1, true),
+			},
+			wantTotal: 2, wantCompleted: 2, wantTotalSecs: 120, wantWatchedSecs: 120, wantPercent: 100,
+		},
+		{
+			name: "last watched lesson done at the end wraps to the first unfinished", chapters: wrapAround,
+			progress: []*LessonProgress{
+				watched(wrapAround[0].Lessons[0], 100, 60, t0, true),
+				watched(wrapAround[0].Lessons[2], 100, 60, t2, true),
+			},
+			wantTotal: 3, wantCompleted: 2, wantTotalSecs: 180, wantWatchedSecs: 120, wantPercent: 66.67,
+			wantNext: &wrapAround[0].Lessons[1],
+		},
+		{
+			name: "out-of-order input resumes by chapter and lesson order", chapters: shuffled,
+			wantTotal: 3, wantTotalSecs: 180, wantNext: &first,
+		},
+		{
+			name: "out-of-order input continues after the last watched lesson", chapters: shuffled,
+			progress:  []*LessonProgress{watched(first, 100, 60, t0, true)},
+			wantTotal: 3, wantCompleted: 1, wantTotalSecs: 180, wantWatchedSecs: 60, wantPercent: 33.33,
+			wantNext: &second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			courseID := uuid.New()
+			summary := SummarizeCourseProgress(courseID, tt.chapters, tt.progress)
+
+			if summary.CourseID != courseID {
+				t.Fatalf("CourseID = %s, want %s", summary.CourseID, courseID)
+			}
+			if summary.TotalLessons != tt.wantTotal || summary.CompletedLessons != tt.wantCompleted {
+				t.Fatalf("lessons = %d/%d, want %d/%d", summary.CompletedLessons, summary.TotalLessons, tt.wantCompleted, tt.wantTotal)
+			}
+			if summary.TotalSeconds != tt.wantTotalSecs || summary.WatchedSeconds != tt.wantWatchedSecs {
+				t.Fatalf("seconds = %d/%d, want %d/%d", summary.WatchedSeconds, summary.TotalSeconds, tt.wantWatchedSecs, tt.wantTotalSecs)
+			}
+			if summary.PercentComplete != tt.wantPercent {
+				t.Fatalf("PercentComplete = %v, want %v", summary.PercentComplete, tt.wantPercent)
+			}
+
+			switch {
+			case tt.wantNext == nil && summary.NextLesson != nil:
+				t.Fatalf("NextLesson = %+v, want nil", summary.NextLesson)
+			case tt.wantNext != nil && summary.NextLesson == nil:
+				t.Fatalf("NextLesson = nil, want %s", tt.wantNext.ID)
+			case tt.wantNext != nil:
+				next := summary.NextLesson
+				if next.LessonID != tt.wantNext.ID || next.ChapterID != tt.wantNext.ChapterID || next.PositionSeconds != tt.wantPosition {
+					t.Fatalf("NextLesson = %+v, want lesson %s at %ds", next, tt.wantNext.ID, tt.wantPosition)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchedPercent(t *testing.T) {
+	reported := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name          string
+		position      int
+		reported      *float64
+		completed     bool
+		duration      int
+		want          float64
+		wantCompleted bool
+	}{
+		{name: "position over duration", position: 150, duration: 300, want: 50},
+		{name: "reported percent wins over position", position: 10, reported: reported(42.5), duration: 300, want: 42.5},
+		{name: "rounded to two decimals", position: 1, duration: 3, want: 33.33},
+		{name: "just below threshold", reported: reported(89.99), duration: 300, want: 89.99},
+		{name: "threshold completes", position: 270, duration: 300, want: 90, wantCompleted: true},
+		{name: "completed flag is always 100", position: 5, completed: true, duration: 300, want: 100, wantCompleted: true},
+		{name: "zero duration without report", position: 30, want: 0},
+		{name: "negative position clamped", position: -10, duration: 300, want: 0},
+		{name: "position past the end clamped", position: 400, duration: 300, want: 100, wantCompleted: true},
+		{name: "reported percent clamped", reported: reported(120), duration: 300, want: 100, wantCompleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WatchedPercent(tt.position, tt.reported, tt.completed, tt.duration)
+			if got != tt.want {
+				t.Fatalf("WatchedPercent = %v, want %v", got, tt.want)
+			}
+			if done := IsLessonCompleted(got, tt.completed); done != tt.wantCompleted {
+				t.Fatalf("IsLessonCompleted(%v, %v) = %v, want %v", got, tt.completed, done, tt.wantCompleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ID aturan readiness (stabil, dipakai BFF untuk menampilkan checklist)
+const (
+	RuleDescription = "description"
+	RuleThumbnail   = "thumbnail"
+	RuleChapters    = "chapters"
+	RuleLessons     = "lessons"
+	RuleMedia       = "lesson_media"
+	RulePrice       = "price"
+	RuleCategory    = "category"
+	RuleLevel       = "level"
+)
+
+// ReadinessConfig mengatur ambang aturan readiness
+type ReadinessConfig struct {
+	MinChapters int // Jumlah chapter minimum (COURSE_MIN_CHAPTERS, >= 1)
+}
+
+// ReadinessRule adalah hasil satu aturan
+type ReadinessRule struct {
+	ID      string      `json:"id"`
+	Passed  bool        `json:"passed"`
+	Message string      `json:"message,omitempty"` // Diisi jika gagal
+	NodeIDs []uuid.UUID `json:"nodeIds,omitempty"` // Chapter/lesson penyebab gagal
+}
+
+// ReadinessReport adalah hasil pengecekan seluruh aturan
+type ReadinessReport struct {
+	CourseID uuid.UUID       `json:"courseId"`
+	Ready    bool            `json:"ready"`
+	Rules    []ReadinessRule `json:"rules"`
+}
+
+// FailedRules mengembalikan ID aturan yang gagal
+func (r *ReadinessReport) FailedRules() []string {
+	failed := []string{}
+	for _, rule := range r.Rules {
+		if !rule.Passed {
+			failed = append(failed, rule.ID)
+		}
+	}
+	return failed
+}
+
+// ReadinessError dikembalikan jika course belum siap diajukan ke review
+type ReadinessError struct {
+	Report *ReadinessReport
+}
+
+func (e *ReadinessError) Error() string {
+	return fmt.Sprintf("course is not ready for review: %s", strings.Join(e.Report.FailedRules(), ", "))
+}
+
+// CheckReadiness menjalankan semua aturan terhadap course
+// ('course' harus sudah memuat Chapters.Lessons dan Categories)
+func CheckReadiness(course *Course, cfg ReadinessConfig) *ReadinessReport {
+	report := &ReadinessReport{CourseID: course.ID, Ready: true}
+	add := func(id string, passed bool, message string, nodeIDs []uuid.UUID) {
+		rule := ReadinessRule{ID: id, Passed: passed, NodeIDs: nodeIDs}
+		if !passed {
+			rule.Message = message
+			report.Ready = false
+		}
+		report.Rules = append(report.Rules, rule)
+	}
+
+	// 1. Metadata course
+	add(RuleDescription, strings.TrimSpace(course.Description) != "", "Description is required", nil)
+	add(RuleThumbnail, strings.TrimSpace(course.Thumbnail) != "", "Thumbnail is required", nil)
+	add(RuleLevel, course.Level != "", "Level is required", nil)
+	add(RuleCategory, len(course.Categories) > 0, "At least one category is required", nil)
+	add(RulePrice, course.IsFree || course.Price > 0, "Price must be set unless the course is free", nil)
+
+	// 2. Kurikulum
+	add(RuleChapters, len(course.Chapters) >= cfg.MinChapters,
+		fmt.Sprintf("At least %d chapter(s) required", cfg.MinChapters), nil)
+
+	var emptyChapters, missingMedia []uuid.UUID
+	for _, chapter := range course.Chapters {
+		if len(chapter.Lessons) == 0 {
+			emptyChapters = append(emptyChapters, chapter.ID)
+		}
+		for _, lesson := range chapter.Lessons {
+			if strings.TrimSpace(lesson.PlaybackID) == "" || lesson.Duration <= 0 {
+				missingMedia = append(missingMedia, lesson.ID)
+			}
+		}
+	}
+	add(RuleLessons, len(emptyChapters) == 0, "Every chapter needs at least one lesson", emptyChapters)
+	add(RuleMedia, len(missingMedia) == 0, "Every lesson needs a video (playbackId) and duration", missingMedia)
+
+	return report
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// readyCourse membuat course yang lolos semua aturan dengan 'chapters' chapter
+func readyCourse(chapters int) *Course {
+	course := &Course{
+		ID:          uuid.New(),
+		Description: "Belajar Go dari nol",
+		Thumbnail:   "https://cdn.example.com/go.png",
+		Level:       LevelBeginner,
+		Price:       150000,
+		Categories:  []Category{{ID: uuid.New(), Name: "Backend"}},
+	}
+	for i := 0; i < chapters; i++ {
+		course.Chapters = append(course.Chapters, Chapter{
+			ID:      uuid.New(),
+			Lessons: []Lesson{{ID: uuid.New(), PlaybackID: "pb-" + uuid.NewString(), Duration: 300}},
+		})
+	}
+	return course
+}
+
+func TestCheckReadiness(t *testing.T) {
+	emptyChapter := readyCourse(2)
+	emptyChapter.Chapters[1].Lessons = nil
+
+	missingMedia := readyCourse(1)
+	missingMedia.Chapters[0].Lessons[0].PlaybackID = " "
+
+	free := readyCourse(1)
+	free.Price, free.IsFree = 0, true
+
+	unpriced := readyCourse(1)
+	unpriced.Price = 0
+
+	bare := &Course{ID: uuid.New()}
+
+	tests := []struct {
+		name        string
+		course      *Course
+		minChapters int
+		wantFailed  []string
+		wantNodeIDs map[string][]uuid.UUID
+	}{
+		{name: "ready", course: readyCourse(1), minChapters: 1, wantFailed: []string{}},
+		{name: "configured minimum is honoured", course: readyCourse(2), minChapters: 3, wantFailed: []string{RuleChapters}},
+		{name: "minimum met exactly", course: readyCourse(3), minChapters: 3, wantFailed: []string{}},
+		{name: "free course needs no price", course: free, minChapters: 1, wantFailed: []string{}},
+		{name: "paid course needs price", course: unpriced, minChapters: 1, wantFailed: []string{RulePrice}},
+		{
+			name: "empty chapter", course: emptyChapter, minChapters: 1,
+			wantFailed:  []string{RuleLessons},
+			wantNodeIDs: map[string][]uuid.UUID{RuleLessons: {emptyChapter.Chapters[1].ID}},
+		},
+		{
+			name: "lesson without media", course: missingMedia, minChapters: 1,
+			wantFailed:  []string{RuleMedia},
+			wantNodeIDs: map[string][]uuid.UUID{RuleMedia: {missingMedia.Chapters[0].Lessons[0].ID}},
+		},
+		{
+			name: "bare course", course: bare, minChapters: 1,
+			wantFailed: []string{RuleDescription, RuleThumbnail, RuleLevel, RuleCategory, RulePrice, RuleChapters},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckReadiness(tt.course, ReadinessConfig{MinChapters: tt.minChapters})
+
+			if got := report.FailedRules(); !slices.Equal(got, tt.wantFailed) {
+				t.Fatalf("FailedRules() = %v, want %v", got, tt.wantFailed)
+			}
+			if report.Ready != (len(tt.wantFailed) == 0) {
+				t.Fatalf("Ready = %v with failed rules %v", report.Ready, tt.wantFailed)
+			}
+			for _, rule := range report.Rules {
+				if want, ok := tt.wantNodeIDs[rule.ID]; ok && !slices.Equal(rule.NodeIDs, want) {
+					t.Fatalf("rule %s NodeIDs = %v, want %v", rule.ID, rule.NodeIDs, want)
+				}
+				if rule.Passed != (rule.Message == "") {
+					t.Fatalf("rule %s: Passed = %v but Message = %q", rule.ID, rule.Passed, rule.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import "strings"
+
+// AllRoles berisi semua role yang dikirim gateway
+var AllRoles = []Role{RoleTeacher, RoleCurator, RoleAdmin}
+
+// IsValid mengecek apakah role termasuk salah satu konstanta di atas
+func (r Role) IsValid() bool {
+	for _, known := range AllRoles {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRoles membaca daftar role dari header gateway ("TEACHER,CURATOR").
+// Role yang tidak dikenal diabaikan; duplikat dibuang.
+func ParseRoles(raw string) []Role {
+	var roles []Role
+	for _, part := range strings.Split(raw, ",") {
+		role := Role(strings.ToUpper(strings.TrimSpace(part)))
+		if !role.IsValid() || HasRole(roles, role) {
+			continue
+		}
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// HasRole mengecek apakah 'roles' memuat salah satu dari 'wanted'
+func HasRole(roles []Role, wanted ...Role) bool {
+	for _, r := range roles {
+		for _, w := range wanted {
+			if r == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware membuat span server untuk setiap request (melanjutkan trace
+// dari header traceparent gateway) dan mencatat metrik HTTP per route.
+// Dipasang paling awal agar middleware lain ikut terukur.
+func Middleware(m *Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// Route template (misal /internal/courses/:id) agar label tidak meledak
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		// 1. Lanjutkan trace dari gateway (W3C traceparent)
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// 2. Catat hasil
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+
+		m.observeHTTP(c.Request.Method, route, strconv.Itoa(status), time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,97 @@
+package observability
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	gormStartKey = "observability:start"
+	gormSpanKey  = "observability:span"
+)
+
+// InstrumentGORM memasang callback GORM yang membuat span client per query
+// (anak dari span request, lewat db.WithContext) dan mencatat durasinya
+// per operasi & tabel
+func InstrumentGORM(db *gorm.DB, m *Metrics) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before("observability:before_"+h.operation, gormBefore(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after("observability:after_"+h.operation, gormAfter(h.operation, m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gormBefore(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		_, span := tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation", operation),
+			),
+		)
+		db.InstanceSet(gormStartKey, time.Now())
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func gormAfter(operation string, m *Metrics) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		// ErrRecordNotFound adalah hasil normal, bukan kegagalan query
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		status := "ok"
+		if failed {
+			status = "error"
+		}
+
+		if start, ok := db.InstanceGet(gormStartKey); ok {
+			m.observeDBQuery(operation, table, status, time.Since(start.(time.Time)).Seconds())
+		}
+
+		if value, ok := db.InstanceGet(gormSpanKey); ok {
+			span := value.(trace.Span)
+			// SQL tanpa nilai parameter (nilai bisa berisi data pribadi)
+			span.SetAttributes(
+				attribute.String("db.sql.table", table),
+				attribute.String("db.statement", db.Statement.SQL.String()),
+				attribute.Int64("db.rows_affected", db.RowsAffected),
+			)
+			if failed {
+				span.RecordError(db.Error)
+				span.SetStatus(codes.Error, db.Error.Error())
+			}
+			span.End()
+		}
+	}
+}
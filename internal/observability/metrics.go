@@ -0,0 +1,142 @@
+package observability
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace adalah prefix semua metrik milik service ini
+const Namespace = "course_service"
+
+// Metrics memegang semua collector Prometheus service ini.
+// Registry disuntikkan (bukan global) agar test bisa memakai registry sendiri.
+// Semua method aman dipanggil pada *Metrics nil (metrik dimatikan).
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	dbQueryDuration   *prometheus.HistogramVec
+	redisDuration     *prometheus.HistogramVec
+	coursesCreated    prometheus.Counter
+	statusTransitions *prometheus.CounterVec
+}
+
+// NewMetrics membuat & mendaftarkan semua metrik (plus metrik runtime Go)
+// ke registry baru
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		registry: registry,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_total",
+			Help:      "Jumlah request HTTP per route & status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latensi request HTTP per route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Durasi query GORM per operasi & tabel.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table", "status"}),
+		redisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "redis_command_duration_seconds",
+			Help:      "Latensi command Redis.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+		}, []string{"command", "status"}),
+		coursesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "courses_created_total",
+			Help:      "Jumlah course yang dibuat.",
+		}),
+		statusTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "course_status_transitions_total",
+			Help:      "Jumlah transisi status course yang berhasil.",
+		}, []string{"from", "to"}),
+	}
+
+	registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.dbQueryDuration,
+		m.redisDuration,
+		m.coursesCreated,
+		m.statusTransitions,
+	)
+	return m
+}
+
+// Registry mengembalikan registry (misal untuk test atau collector tambahan)
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler adalah endpoint /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDBStats mengekspos statistik pool koneksi (open, in-use, idle, wait, ...)
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// --- Pencatatan ---
+
+func (m *Metrics) observeHTTP(method, route, status string, seconds float64) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(seconds)
+}
+
+func (m *Metrics) observeDBQuery(operation, table, status string, seconds float64) {
+	if m == nil {
+		return
+	}
+	m.dbQueryDuration.WithLabelValues(operation, table, status).Observe(seconds)
+}
+
+func (m *Metrics) observeRedis(command, status string, seconds float64) {
+	if m == nil {
+		return
+	}
+	m.redisDuration.WithLabelValues(command, status).Observe(seconds)
+}
+
+// CourseCreated mencatat satu course baru
+func (m *Metrics) CourseCreated() {
+	if m == nil {
+		return
+	}
+	m.coursesCreated.Inc()
+}
+
+// StatusTransition mencatat satu transisi status yang berhasil disimpan
+func (m *Metrics) StatusTransition(from, to string) {
+	if m == nil {
+		return
+	}
+	m.statusTransitions.WithLabelValues(from, to).Inc()
+}
@@ -0,0 +1,337 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// setupTestTracing memasang TracerProvider dengan exporter in-memory
+// (sinkron, semua span di-sample) dan mengembalikan provider global semula
+// setelah test selesai
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	previous := otel.GetTracerProvider()
+	exporter := tracetest.NewInMemoryExporter()
+
+	shutdown, err := SetupTracing(context.Background(), TracingConfig{
+		ServiceName: "course-service-test",
+		SampleRatio: 1,
+		Exporter:    exporter,
+		Syncer:      true,
+	})
+	if err != nil {
+		t.Fatalf("SetupTracing: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+// spanNamed mencari span (yang sudah selesai) berdasarkan nama
+func spanNamed(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	t.Helper()
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+		names = append(names, span.Name)
+	}
+	t.Fatalf("span %q not found, got %v", name, names)
+	return tracetest.SpanStub{}
+}
+
+// attr membaca satu atribut span (Value kosong jika tidak ada)
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddlewareSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		path       string
+		status     int
+		wantSpan   string
+		wantRoute  string
+		wantStatus codes.Code
+	}{
+		{name: "route template", path: "/internal/courses/0b7e", status: http.StatusOK, wantSpan: "GET /internal/courses/:id", wantRoute: "/internal/courses/:id", wantStatus: codes.Unset},
+		{name: "server error", path: "/internal/courses/0b7e", status: http.StatusServiceUnavailable, wantSpan: "GET /internal/courses/:id", wantRoute: "/internal/courses/:id", wantStatus: codes.Error},
+		{name: "client error is not a span error", path: "/internal/courses/0b7e", status: http.StatusNotFound, wantSpan: "GET /internal/courses/:id", wantRoute: "/internal/courses/:id", wantStatus: codes.Unset},
+		{name: "unmatched route", path: "/nope", status: http.StatusNotFound, wantSpan: "GET unmatched", wantRoute: "unmatched", wantStatus: codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := setupTestTracing(t)
+			metrics := NewMetrics()
+
+			router := gin.New()
+			router.Use(Middleware(metrics))
+			router.GET("/internal/courses/:id", func(c *gin.Context) {
+				// Span request harus ada di context handler (induk span DB/Redis)
+				if !trace.SpanContextFromContext(c.Request.Context()).IsValid() {
+					t.Error("request context has no span")
+				}
+				c.Status(tt.status)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			span := spanNamed(t, exporter, tt.wantSpan)
+			if span.SpanKind != trace.SpanKindServer {
+				t.Fatalf("span kind = %v, want server", span.SpanKind)
+			}
+			if got := attr(span, "http.route").AsString(); got != tt.wantRoute {
+				t.Fatalf("http.route = %q, want %q", got, tt.wantRoute)
+			}
+			if got := attr(span, "url.path").AsString(); got != tt.path {
+				t.Fatalf("url.path = %q, want %q", got, tt.path)
+			}
+			if got := attr(span, "http.response.status_code").AsInt64(); got != int64(tt.status) {
+				t.Fatalf("http.response.status_code = %d, want %d", got, tt.status)
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Fatalf("span status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+
+			// Label metrik memakai template yang sama dengan span
+			count := testutil.ToFloat64(metrics.httpRequests.WithLabelValues(http.MethodGet, tt.wantRoute, strconv.Itoa(tt.status)))
+			if count != 1 {
+				t.Fatalf("http_requests_total{route=%q} = %v, want 1", tt.wantRoute, count)
+			}
+		})
+	}
+}
+
+func TestMiddlewareContinuesGatewayTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := setupTestTracing(t)
+
+	router := gin.New()
+	router.Use(Middleware(nil))
+	router.GET("/internal/courses/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/internal/courses/0b7e", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := spanNamed(t, exporter, "GET /internal/courses/:id")
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace ID = %s, want the gateway's trace", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Fatalf("parent span ID = %s, want the gateway's span", got)
+	}
+}
+
+// gormTestCourse cukup untuk membangun SQL di mode DryRun
+type gormTestCourse struct {
+	ID    int
+	Title string
+}
+
+func (gormTestCourse) TableName() string { return "courses" }
+
+// dryRunDB membuka GORM tanpa koneksi: SQL dibangun & callback dijalankan,
+// tapi tidak ada yang dikirim ke database
+func dryRunDB(t *testing.T, m *Metrics) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 user=test dbname=test sslmode=disable"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true, // BEGIN butuh koneksi
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := InstrumentGORM(db, m); err != nil {
+		t.Fatalf("InstrumentGORM: %v", err)
+	}
+	return db
+}
+
+func TestGORMSpans(t *testing.T) {
+	db := dryRunDB(t, NewMetrics())
+
+	tests := []struct {
+		name       string
+		run        func(tx *gorm.DB) // tx sudah membawa context dengan span induk
+		wantSpan   string
+		wantStatus codes.Code
+	}{
+		{
+			name:     "create",
+			run:      func(tx *gorm.DB) { tx.Create(&gormTestCourse{Title: "Go"}) },
+			wantSpan: "gorm.create",
+		},
+		{
+			name:     "query",
+			run:      func(tx *gorm.DB) { tx.Where("title = ?", "rahasia").Find(&[]gormTestCourse{}) },
+			wantSpan: "gorm.query",
+		},
+		{
+			name:     "update",
+			run:      func(tx *gorm.DB) { tx.Model(&gormTestCourse{ID: 1}).Update("title", "Go 2") },
+			wantSpan: "gorm.update",
+		},
+		{
+			name: "failed query",
+			run: func(tx *gorm.DB) {
+				tx = tx.Table("courses")
+				tx.AddError(errors.New("connection reset"))
+				tx.Find(&[]gormTestCourse{})
+			},
+			wantSpan:   "gorm.query",
+			wantStatus: codes.Error,
+		},
+		{
+			name: "record not found is not an error",
+			run: func(tx *gorm.DB) {
+				tx = tx.Table("courses")
+				tx.AddError(gorm.ErrRecordNotFound)
+				tx.Find(&[]gormTestCourse{})
+			},
+			wantSpan:   "gorm.query",
+			wantStatus: codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := setupTestTracing(t)
+
+			ctx, parent := tracer().Start(context.Background(), "request")
+			tt.run(db.WithContext(ctx))
+			parent.End()
+
+			span := spanNamed(t, exporter, tt.wantSpan)
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Fatal("query span is not a child of the request span")
+			}
+			if span.SpanKind != trace.SpanKindClient {
+				t.Fatalf("span kind = %v, want client", span.SpanKind)
+			}
+			if got := attr(span, "db.sql.table").AsString(); got != "courses" {
+				t.Fatalf("db.sql.table = %q, want courses", got)
+			}
+			if got := attr(span, "db.system").AsString(); got != "postgresql" {
+				t.Fatalf("db.system = %q, want postgresql", got)
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Fatalf("span status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGORMSpanOmitsParameterValues(t *testing.T) {
+	db := dryRunDB(t, nil)
+	exporter := setupTestTracing(t)
+
+	db.WithContext(context.Background()).Where("title = ?", "rahasia").Find(&[]gormTestCourse{})
+
+	statement := attr(spanNamed(t, exporter, "gorm.query"), "db.statement").AsString()
+	if statement == "" || strings.Contains(statement, "rahasia") {
+		t.Fatalf("db.statement = %q, want SQL with placeholders only", statement)
+	}
+}
+
+func TestRedisSpans(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	InstrumentRedis(client, NewMetrics())
+
+	mr.Set("greeting", "halo") // Bukan angka: INCR gagal
+
+	tests := []struct {
+		name       string
+		run        func(ctx context.Context)
+		wantSpan   string
+		wantStatus codes.Code
+		wantAttrs  map[attribute.Key]int64
+	}{
+		{
+			name:     "get",
+			run:      func(ctx context.Context) { client.Get(ctx, "greeting") },
+			wantSpan: "redis.get",
+		},
+		{
+			name:     "cache miss is not an error",
+			run:      func(ctx context.Context) { client.Get(ctx, "missing") },
+			wantSpan: "redis.get",
+		},
+		{
+			name:       "command error",
+			run:        func(ctx context.Context) { client.Incr(ctx, "greeting") },
+			wantSpan:   "redis.incr",
+			wantStatus: codes.Error,
+		},
+		{
+			name: "pipeline",
+			run: func(ctx context.Context) {
+				pipe := client.Pipeline()
+				pipe.Get(ctx, "greeting")
+				pipe.Set(ctx, "other", "x", 0)
+				pipe.Del(ctx, "other")
+				_, _ = pipe.Exec(ctx)
+			},
+			wantSpan:  "redis.pipeline",
+			wantAttrs: map[attribute.Key]int64{"db.redis.pipeline_length": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := setupTestTracing(t)
+
+			ctx, parent := tracer().Start(context.Background(), "request")
+			tt.run(ctx)
+			parent.End()
+
+			span := spanNamed(t, exporter, tt.wantSpan)
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Fatal("redis span is not a child of the request span")
+			}
+			if got := attr(span, "db.system").AsString(); got != "redis" {
+				t.Fatalf("db.system = %q, want redis", got)
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Fatalf("span status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+			for key, want := range tt.wantAttrs {
+				if got := attr(span, key).AsInt64(); got != want {
+					t.Fatalf("%s = %d, want %d", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentRedis memasang hook yang membuat span client & mencatat
+// latensi per command (pipeline dicatat sebagai satu command "pipeline")
+func InstrumentRedis(client *redis.Client, m *Metrics) {
+	client.AddHook(&redisHook{metrics: m})
+}
+
+type redisHook struct {
+	metrics *Metrics
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name := strings.ToLower(cmd.Name())
+		ctx, span, start := h.start(ctx, name)
+		err := next(ctx, cmd)
+		h.finish(span, start, name, err)
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span, start := h.start(ctx, "pipeline")
+		span.SetAttributes(attribute.Int("db.redis.pipeline_length", len(cmds)))
+		err := next(ctx, cmds)
+		h.finish(span, start, "pipeline", err)
+		return err
+	}
+}
+
+func (h *redisHook) start(ctx context.Context, command string) (context.Context, trace.Span, time.Time) {
+	ctx, span := tracer().Start(ctx, "redis."+command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", command),
+		),
+	)
+	return ctx, span, time.Now()
+}
+
+func (h *redisHook) finish(span trace.Span, start time.Time, command string, err error) {
+	// redis.Nil = key tidak ada (hasil normal, misal cache miss)
+	status := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	h.metrics.observeRedis(command, status, time.Since(start).Seconds())
+}
@@ -0,0 +1,76 @@
+package observability
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName adalah nama instrumentation scope untuk span service ini
+const TracerName = "github.com/wtppaul/course-service"
+
+// TracingConfig mengatur tracing
+type TracingConfig struct {
+	ServiceName string
+	SampleRatio float64 // 0..1, untuk trace baru (parent dari gateway tetap diikuti)
+
+	// Exporter tujuan span. nil = span tidak diekspor (trace context tetap
+	// diteruskan). Test bisa memakai tracetest.NewInMemoryExporter().
+	Exporter sdktrace.SpanExporter
+
+	// Syncer: ekspor span langsung saat selesai (untuk test), bukan batch
+	Syncer bool
+}
+
+// SetupTracing memasang TracerProvider & propagator W3C (traceparent +
+// baggage) secara global. Kembalikan fungsi shutdown untuk flush span.
+func SetupTracing(ctx context.Context, cfg TracingConfig) (func(context.Context) error, error) {
+	// Propagator selalu dipasang agar trace dari gateway tidak terputus
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+	if cfg.Syncer {
+		opts = append(opts, sdktrace.WithSyncer(cfg.Exporter))
+	} else {
+		opts = append(opts, sdktrace.WithBatcher(cfg.Exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewOTLPExporter membuat exporter OTLP/HTTP. Endpoint & header dibaca
+// dari env standar OTEL_EXPORTER_OTLP_* oleh library.
+func NewOTLPExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx)
+}
+
+// tracer mengambil tracer dari provider global saat dipakai (bukan saat
+// init), agar provider yang dipasang belakangan (misal di test) ikut terpakai
+func tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
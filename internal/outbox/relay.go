@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// Config mengatur perilaku relay
+type Config struct {
+	Stream       string        // Nama Redis Stream tujuan
+	MaxLen       int64         // Batas panjang stream (approx), 0 = tanpa batas
+	BatchSize    int           // Jumlah event per polling
+	PollInterval time.Duration // Jeda antar polling saat outbox kosong
+	MaxBackoff   time.Duration // Batas atas jeda retry
+}
+
+// Relay membaca 'outbox_events' yang belum terkirim dan mem-publish-nya
+// ke Redis Streams. Pengiriman bersifat at-least-once: jika proses mati
+// setelah XADD tapi sebelum commit, event akan dikirim ulang, jadi
+// consumer harus deduplikasi berdasarkan 'id' di envelope.
+type Relay struct {
+	db     *gorm.DB
+	client *redis.Client
+	cfg    Config
+}
+
+func NewRelay(db *gorm.DB, client *redis.Client, cfg Config) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	return &Relay{db: db, client: client, cfg: cfg}
+}
+
+// Run menjalankan loop relay sampai ctx dibatalkan
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Proses batch berturut-turut selama masih ada event
+		for {
+			n, err := r.ProcessBatch(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "outbox batch failed", "error", err)
+				break
+			}
+			if n < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch mengirim satu batch event dan mengembalikan jumlah yang diproses.
+// Baris dikunci dengan FOR UPDATE SKIP LOCKED sehingga beberapa replika
+// bisa menjalankan relay bersamaan tanpa mengirim event yang sama.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	processed := 0
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Ambil event yang siap dikirim
+		var events []models.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("created_at ASC").
+			Limit(r.cfg.BatchSize).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+
+		// 2. Kirim satu per satu; gagal kirim dijadwalkan ulang (backoff)
+		for i := range events {
+			event := &events[i]
+			processed++
+
+			if err := r.publish(ctx, event); err != nil {
+				event.Attempts++
+				updates := map[string]interface{}{
+					"attempts":        event.Attempts,
+					"last_error":      err.Error(),
+					"next_attempt_at": time.Now().Add(r.backoff(event.Attempts)),
+				}
+				if err := tx.Model(event).Updates(updates).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(event).Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return processed, err
+}
+
+// publish mengirim satu event ke Redis Stream
+func (r *Relay) publish(ctx context.Context, event *models.OutboxEvent) error {
+	args := &redis.XAddArgs{
+		Stream: r.cfg.Stream,
+		Values: map[string]interface{}{
+			"id":       event.ID.String(),
+			"type":     event.EventType,
+			"envelope": string(event.Payload),
+		},
+	}
+	if r.cfg.MaxLen > 0 {
+		args.MaxLen = r.cfg.MaxLen
+		args.Approx = true
+	}
+	return r.client.XAdd(ctx, args).Err()
+}
+
+// backoff menghitung jeda retry eksponensial: 2s, 4s, 8s, ... (maks MaxBackoff)
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 0; i < attempts && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,97 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+)
+
+// RegisterValidatorTagNames membuat validator gin melaporkan nama field
+// sesuai tag JSON (misal "lessonId", bukan "LessonID"), agar path di
+// 'errors' sama dengan yang dikirim client. Dipanggil sekali saat startup.
+func RegisterValidatorTagNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// InvalidBody menerjemahkan error dari ShouldBindJSON/ShouldBindQuery:
+//   - pelanggaran tag 'binding' -> VALIDATION_FAILED dengan detail per field
+//   - tipe JSON salah -> VALIDATION_FAILED (INVALID_TYPE) untuk field tsb
+//   - selain itu (JSON rusak, body kosong) -> INVALID_BODY
+func InvalidBody(err error) error {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]apperr.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, apperr.FieldError{
+				Field: fieldPath(fe),
+				Code:  validationCode(fe.Tag()),
+				Param: fe.Param(),
+			})
+		}
+		return ErrValidation.Wrap(err).WithFields(fields...)
+	case errors.As(err, &typeErr):
+		return ErrValidation.Wrap(err).WithFields(apperr.FieldError{
+			Field: typeErr.Field,
+			Code:  "INVALID_TYPE",
+			Param: typeErr.Type.String(),
+		})
+	default:
+		return ErrInvalidBody.Wrap(err)
+	}
+}
+
+// fieldPath membuang nama struct di depan namespace validator
+// ("couponInput.courseId" -> "courseId"). Struct anonim tidak punya
+// prefix ("items[0].lessonId"); dikenali karena segmen pertamanya
+// berbeda dengan versi nama Go-nya ("Items[0]").
+func fieldPath(fe validator.FieldError) string {
+	root, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return root
+	}
+	if structRoot, _, _ := strings.Cut(fe.StructNamespace(), "."); structRoot != root {
+		return fe.Namespace()
+	}
+	return path
+}
+
+// validationCode memetakan tag validator ke kode field yang stabil
+func validationCode(tag string) string {
+	switch tag {
+	case "required", "required_if", "required_with", "required_without":
+		return "REQUIRED"
+	case "min", "gte", "gt":
+		return "MIN"
+	case "max", "lte", "lt":
+		return "MAX"
+	case "len":
+		return "LEN"
+	case "oneof":
+		return "ONEOF"
+	default:
+		return "INVALID"
+	}
+}
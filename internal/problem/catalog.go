@@ -0,0 +1,229 @@
+package problem
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+)
+
+// Bahasa respons yang didukung; urutan pertama = default
+var supportedLanguages = []language.Tag{language.English, language.Indonesian}
+
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+// negotiateLanguage memilih "en" atau "id" dari header Accept-Language
+func negotiateLanguage(header string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return supportedLanguages[0]
+	}
+	_, index, _ := languageMatcher.Match(tags...)
+	return supportedLanguages[index]
+}
+
+// message adalah teks satu kode error. Detail boleh berisi placeholder
+// {nama} yang diisi dari Params error.
+type message struct {
+	Title  string
+	Detail string
+}
+
+// lookup mengambil pesan untuk 'code'. Kode yang belum ada di katalog
+// jatuh ke pesan INTERNAL_ERROR agar respons tidak pernah kosong.
+func lookup(lang language.Tag, code string) message {
+	catalog := catalogs[lang]
+	if msg, ok := catalog[code]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[supportedLanguages[0]][code]; ok {
+		return msg
+	}
+	return catalog[ErrInternal.Code]
+}
+
+// interpolate mengganti {nama} dengan nilai Params[nama]
+func interpolate(text string, params map[string]any) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for key, value := range params {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// localizeFields mengisi Message setiap FieldError dari katalog field
+func localizeFields(lang language.Tag, fields []apperr.FieldError) []apperr.FieldError {
+	localized := make([]apperr.FieldError, len(fields))
+	for i, field := range fields {
+		text, ok := fieldCatalogs[lang][field.Code]
+		if !ok {
+			text = fieldCatalogs[lang]["INVALID"]
+		}
+		field.Message = interpolate(text, map[string]any{"param": field.Param})
+		localized[i] = field
+	}
+	return localized
+}
+
+// === KATALOG PESAN ===
+// Kunci = kode stabil. Setiap kode baru WAJIB ditambahkan di kedua bahasa.
+
+var catalogs = map[language.Tag]map[string]message{
+	language.English: {
+		// Umum
+		"INTERNAL_ERROR":     {"Internal server error", "An unexpected error occurred. Please try again later."},
+		"INVALID_ID":         {"Invalid ID", "Parameter '{param}' is not a valid ID."},
+		"INVALID_BODY":       {"Malformed request body", "The request body could not be read."},
+		"VALIDATION_FAILED":  {"Validation failed", "One or more fields are invalid."},
+		"RESOURCE_NOT_FOUND": {"Resource not found", "The requested resource does not exist."},
+		"UNAUTHENTICATED":    {"Authentication required", "This endpoint requires a user context."},
+		"USER_ROLE_MISSING":  {"Authentication required", "The user role header is missing."},
+		"INSUFFICIENT_ROLE":  {"Forbidden", "Your role is not allowed to perform this action."},
+		"IF_MATCH_REQUIRED":  {"Precondition required", "The If-Match header is required for this request."},
+		"INVALID_IF_MATCH":   {"Invalid If-Match header", "The If-Match header must contain exactly one strong ETag, e.g. \"7\"."},
+		"AUTH_ID_REQUIRED":   {"Missing user ID", "Parameter 'authId' is required."},
+		"BATCH_TOO_LARGE":    {"Batch too large", "A batch may contain at most {max} items."},
+
+		// Signature & idempotency
+		"SIGNATURE_KEY_UNKNOWN":       {"Forbidden", "Unknown signing key."},
+		"SIGNATURE_TIMESTAMP_INVALID": {"Forbidden", "Invalid signature timestamp."},
+		"SIGNATURE_EXPIRED":           {"Forbidden", "Signature timestamp is outside the allowed window."},
+		"SIGNATURE_INVALID":           {"Forbidden", "Invalid signature."},
+		"BODY_TOO_LARGE":              {"Request body too large", "The request body may be at most {max} bytes."},
+		"IDEMPOTENCY_KEY_TOO_LONG":    {"Invalid Idempotency-Key", "Idempotency-Key may be at most {max} characters."},
+		"IDEMPOTENCY_IN_PROGRESS":     {"Request in progress", "A request with this Idempotency-Key is still being processed."},
+		"IDEMPOTENCY_KEY_REUSED":      {"Idempotency-Key reused", "This Idempotency-Key was already used with a different request."},
+
+		// Tidak ditemukan
+		"COURSE_NOT_FOUND":     {"Course not found", "The course does not exist."},
+		"CHAPTER_NOT_FOUND":    {"Chapter not found", "The chapter does not exist."},
+		"LESSON_NOT_FOUND":     {"Lesson not found", "The lesson does not exist."},
+		"CATEGORY_NOT_FOUND":   {"Category not found", "The category does not exist."},
+		"TAG_NOT_FOUND":        {"Tag not found", "The tag does not exist."},
+		"COUPON_NOT_FOUND":     {"Coupon not found", "The coupon does not exist or has expired."},
+		"REDEMPTION_NOT_FOUND": {"Redemption not found", "The coupon redemption does not exist."},
+		"ENROLLMENT_NOT_FOUND": {"Enrollment not found", "No active enrollment was found."},
+
+		// Kepemilikan & status
+		"NOT_OWNER":                   {"Forbidden", "You do not own this course."},
+		"INVALID_STATUS":              {"Invalid status", "Status '{status}' is not a known course status."},
+		"INVALID_STATUS_TRANSITION":   {"Invalid status transition", "A course cannot move from {currentStatus} to {requestedStatus}."},
+		"STATUS_TRANSITION_FORBIDDEN": {"Status change not allowed", "Your role may not move a course from {currentStatus} to {requestedStatus}."},
+		"COURSE_NOT_READY":            {"Course not ready", "The course does not meet the requirements for review yet."},
+
+		// Konflik
+		"VERSION_CONFLICT":            {"Version conflict", "The resource was modified by another request. Reload it and try again."},
+		"TAG_NAME_TAKEN":              {"Tag name taken", "A tag with this name already exists."},
+		"CATEGORY_NAME_TAKEN":         {"Category name taken", "A category with this name already exists."},
+		"CATEGORY_CYCLE":              {"Invalid category move", "A category cannot be moved under itself or its descendants."},
+		"CATEGORY_HAS_CHILDREN":       {"Category has children", "Move or delete the child categories first."},
+		"COUPON_EXHAUSTED":            {"Coupon exhausted", "The coupon has reached its maximum uses."},
+		"REDEMPTION_ALREADY_RELEASED": {"Redemption already released", "The coupon redemption was already released."},
+
+		// Aturan bisnis & input
+		"COUPON_NOT_APPLICABLE":     {"Coupon not applicable", "The coupon does not apply to this course."},
+		"COURSE_NOT_FREE":           {"Course is not free", "Only free courses can be enrolled without payment."},
+		"INVALID_CURRICULUM":        {"Invalid curriculum", "The curriculum contains invalid chapters or lessons."},
+		"LESSON_MOVE_CROSS_COURSE":  {"Invalid lesson move", "A lesson can only be moved to a chapter in the same course."},
+		"TAG_MERGE_SELF":            {"Invalid tag merge", "A tag cannot be merged into itself."},
+		"CHAPTER_NOT_IN_COURSE":     {"Chapter not in course", "Chapter {chapterId} does not exist or does not belong to this course."},
+		"LESSON_NOT_IN_CHAPTER":     {"Lesson not in chapter", "Lesson {lessonId} does not exist or does not belong to this chapter."},
+		"REORDER_MISMATCH":          {"Incomplete reorder", "The new order must list every chapter or lesson exactly once."},
+		"INVALID_ENROLLMENT_SOURCE": {"Invalid enrollment source", "Enrollment source '{source}' is not supported."},
+		"EXPIRES_AT_NOT_FUTURE":     {"Invalid expiry", "expiresAt must be in the future."},
+	},
+	language.Indonesian: {
+		// Umum
+		"INTERNAL_ERROR":     {"Kesalahan server", "Terjadi kesalahan tak terduga. Silakan coba lagi nanti."},
+		"INVALID_ID":         {"ID tidak valid", "Parameter '{param}' bukan ID yang valid."},
+		"INVALID_BODY":       {"Body request tidak valid", "Body request tidak dapat dibaca."},
+		"VALIDATION_FAILED":  {"Validasi gagal", "Satu atau lebih field tidak valid."},
+		"RESOURCE_NOT_FOUND": {"Data tidak ditemukan", "Data yang diminta tidak ada."},
+		"UNAUTHENTICATED":    {"Autentikasi diperlukan", "Endpoint ini memerlukan konteks user."},
+		"USER_ROLE_MISSING":  {"Autentikasi diperlukan", "Header role user tidak ada."},
+		"INSUFFICIENT_ROLE":  {"Akses ditolak", "Role Anda tidak diizinkan melakukan aksi ini."},
+		"IF_MATCH_REQUIRED":  {"Prasyarat diperlukan", "Header If-Match wajib dikirim untuk request ini."},
+		"INVALID_IF_MATCH":   {"Header If-Match tidak valid", "Header If-Match harus berisi tepat satu ETag kuat, misal \"7\"."},
+		"AUTH_ID_REQUIRED":   {"ID user tidak ada", "Parameter 'authId' wajib diisi."},
+		"BATCH_TOO_LARGE":    {"Batch terlalu besar", "Satu batch berisi paling banyak {max} item."},
+
+		// Signature & idempotency
+		"SIGNATURE_KEY_UNKNOWN":       {"Akses ditolak", "Signing key tidak dikenal."},
+		"SIGNATURE_TIMESTAMP_INVALID": {"Akses ditolak", "Timestamp signature tidak valid."},
+		"SIGNATURE_EXPIRED":           {"Akses ditolak", "Timestamp signature di luar jendela waktu yang diizinkan."},
+		"SIGNATURE_INVALID":           {"Akses ditolak", "Signature tidak valid."},
+		"BODY_TOO_LARGE":              {"Body request terlalu besar", "Body request paling besar {max} byte."},
+		"IDEMPOTENCY_KEY_TOO_LONG":    {"Idempotency-Key tidak valid", "Idempotency-Key paling panjang {max} karakter."},
+		"IDEMPOTENCY_IN_PROGRESS":     {"Request sedang diproses", "Request dengan Idempotency-Key ini masih diproses."},
+		"IDEMPOTENCY_KEY_REUSED":      {"Idempotency-Key sudah dipakai", "Idempotency-Key ini sudah dipakai untuk request yang berbeda."},
+
+		// Tidak ditemukan
+		"COURSE_NOT_FOUND":     {"Course tidak ditemukan", "Course tidak ada."},
+		"CHAPTER_NOT_FOUND":    {"Chapter tidak ditemukan", "Chapter tidak ada."},
+		"LESSON_NOT_FOUND":     {"Lesson tidak ditemukan", "Lesson tidak ada."},
+		"CATEGORY_NOT_FOUND":   {"Kategori tidak ditemukan", "Kategori tidak ada."},
+		"TAG_NOT_FOUND":        {"Tag tidak ditemukan", "Tag tidak ada."},
+		"COUPON_NOT_FOUND":     {"Kupon tidak ditemukan", "Kupon tidak ada atau sudah kedaluwarsa."},
+		"REDEMPTION_NOT_FOUND": {"Pemakaian kupon tidak ditemukan", "Data pemakaian kupon tidak ada."},
+		"ENROLLMENT_NOT_FOUND": {"Enrollment tidak ditemukan", "Tidak ada enrollment yang aktif."},
+
+		// Kepemilikan & status
+		"NOT_OWNER":                   {"Akses ditolak", "Anda bukan pemilik course ini."},
+		"INVALID_STATUS":              {"Status tidak valid", "Status '{status}' bukan status course yang dikenal."},
+		"INVALID_STATUS_TRANSITION":   {"Transisi status tidak valid", "Course tidak bisa berpindah dari {currentStatus} ke {requestedStatus}."},
+		"STATUS_TRANSITION_FORBIDDEN": {"Perubahan status tidak diizinkan", "Role Anda tidak boleh memindahkan course dari {currentStatus} ke {requestedStatus}."},
+		"COURSE_NOT_READY":            {"Course belum siap", "Course belum memenuhi syarat untuk diajukan ke review."},
+
+		// Konflik
+		"VERSION_CONFLICT":            {"Konflik versi", "Data sudah diubah oleh request lain. Muat ulang lalu coba lagi."},
+		"TAG_NAME_TAKEN":              {"Nama tag sudah dipakai", "Tag dengan nama ini sudah ada."},
+		"CATEGORY_NAME_TAKEN":         {"Nama kategori sudah dipakai", "Kategori dengan nama ini sudah ada."},
+		"CATEGORY_CYCLE":              {"Pemindahan kategori tidak valid", "Kategori tidak bisa dipindah ke bawah dirinya sendiri atau turunannya."},
+		"CATEGORY_HAS_CHILDREN":       {"Kategori masih punya sub-kategori", "Pindahkan atau hapus sub-kategori terlebih dahulu."},
+		"COUPON_EXHAUSTED":            {"Kupon habis", "Kupon sudah mencapai batas pemakaian."},
+		"REDEMPTION_ALREADY_RELEASED": {"Kupon sudah dikembalikan", "Pemakaian kupon ini sudah dibatalkan sebelumnya."},
+
+		// Aturan bisnis & input
+		"COUPON_NOT_APPLICABLE":     {"Kupon tidak berlaku", "Kupon tidak berlaku untuk course ini."},
+		"COURSE_NOT_FREE":           {"Course tidak gratis", "Hanya course gratis yang bisa diikuti tanpa pembayaran."},
+		"INVALID_CURRICULUM":        {"Kurikulum tidak valid", "Kurikulum berisi chapter atau lesson yang tidak valid."},
+		"LESSON_MOVE_CROSS_COURSE":  {"Pemindahan lesson tidak valid", "Lesson hanya bisa dipindah ke chapter di course yang sama."},
+		"TAG_MERGE_SELF":            {"Penggabungan tag tidak valid", "Tag tidak bisa digabung ke dirinya sendiri."},
+		"CHAPTER_NOT_IN_COURSE":     {"Chapter bukan milik course", "Chapter {chapterId} tidak ada atau bukan milik course ini."},
+		"LESSON_NOT_IN_CHAPTER":     {"Lesson bukan milik chapter", "Lesson {lessonId} tidak ada atau bukan milik chapter ini."},
+		"REORDER_MISMATCH":          {"Urutan tidak lengkap", "Urutan baru harus memuat setiap chapter atau lesson tepat satu kali."},
+		"INVALID_ENROLLMENT_SOURCE": {"Sumber enrollment tidak valid", "Sumber enrollment '{source}' tidak didukung."},
+		"EXPIRES_AT_NOT_FUTURE":     {"Masa berlaku tidak valid", "expiresAt harus di masa depan."},
+	},
+}
+
+// Pesan per kode validasi field ({param} = batas/opsi dari tag validator)
+var fieldCatalogs = map[language.Tag]map[string]string{
+	language.English: {
+		"REQUIRED":     "This field is required.",
+		"MIN":          "Must be at least {param}.",
+		"MAX":          "Must be at most {param}.",
+		"LEN":          "Must have length {param}.",
+		"ONEOF":        "Must be one of: {param}.",
+		"DUPLICATE":    "Duplicate value.",
+		"NOT_FOUND":    "Referenced item does not exist.",
+		"INVALID_TYPE": "Has the wrong type; expected {param}.",
+		"INVALID":      "Is invalid.",
+	},
+	language.Indonesian: {
+		"REQUIRED":     "Field ini wajib diisi.",
+		"MIN":          "Minimal {param}.",
+		"MAX":          "Maksimal {param}.",
+		"LEN":          "Panjang harus {param}.",
+		"ONEOF":        "Harus salah satu dari: {param}.",
+		"DUPLICATE":    "Nilai duplikat.",
+		"NOT_FOUND":    "Data yang dirujuk tidak ada.",
+		"INVALID_TYPE": "Tipe salah; seharusnya {param}.",
+		"INVALID":      "Tidak valid.",
+	},
+}
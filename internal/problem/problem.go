@@ -0,0 +1,158 @@
+// Package problem memetakan error (terutama apperr.Error) ke respons
+// RFC 7807 (application/problem+json) di satu tempat. Handler & middleware
+// cukup memanggil Respond(c, err); status HTTP, kode stabil dan teks
+// (Indonesia/Inggris sesuai Accept-Language) ditentukan di sini.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// ContentType adalah media type respons error (RFC 7807)
+const ContentType = "application/problem+json"
+
+// typePrefix + kode (huruf kecil, '-') = URI 'type' problem
+const typePrefix = "urn:course-service:problem:"
+
+// Error level request (bukan dari repository)
+var (
+	ErrInternal    = apperr.NewError(apperr.KindInternal, "INTERNAL_ERROR", "internal server error")
+	ErrInvalidID   = apperr.NewError(apperr.KindInvalidInput, "INVALID_ID", "invalid resource ID format")
+	ErrInvalidBody = apperr.NewError(apperr.KindInvalidInput, "INVALID_BODY", "request body is malformed")
+	ErrValidation  = apperr.NewError(apperr.KindInvalidInput, "VALIDATION_FAILED", "request body failed validation")
+	ErrNotFound    = apperr.NewError(apperr.KindNotFound, "RESOURCE_NOT_FOUND", "resource not found")
+)
+
+// InvalidID: parameter URL 'param' bukan UUID yang valid
+func InvalidID(param string) error {
+	return ErrInvalidID.With("param", param)
+}
+
+// Respond mengirim 'err' sebagai problem+json lalu meng-abort request.
+// Error yang tidak dikenal menjadi 500 INTERNAL_ERROR.
+func Respond(c *gin.Context, err error) {
+	domainErr := FromError(err)
+	status := StatusOf(domainErr.Kind)
+
+	// Error asli hanya dicatat di log. ErrInternal yang dikirim langsung
+	// (misal dari recovery) sudah dicatat oleh pemanggilnya.
+	if status >= http.StatusInternalServerError && !errors.Is(err, ErrInternal) {
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).ErrorContext(ctx, "request failed", "error", err, "code", domainErr.Code)
+		_ = c.Error(err)
+	}
+
+	lang := negotiateLanguage(c.GetHeader("Accept-Language"))
+	msg := lookup(lang, domainErr.Code)
+
+	// Params ikut sebagai extension member (misal allowedNext),
+	// tapi tidak boleh menimpa member standar
+	body := gin.H{}
+	for key, value := range domainErr.Params {
+		body[key] = value
+	}
+	body["type"] = typePrefix + strings.ToLower(strings.ReplaceAll(domainErr.Code, "_", "-"))
+	body["title"] = msg.Title
+	body["status"] = status
+	body["detail"] = interpolate(msg.Detail, domainErr.Params)
+	body["instance"] = c.Request.URL.Path
+	body["code"] = domainErr.Code
+	if requestID := c.GetString("requestID"); requestID != "" {
+		body["requestId"] = requestID
+	}
+	if len(domainErr.Fields) > 0 {
+		body["errors"] = localizeFields(lang, domainErr.Fields)
+	}
+
+	c.Header("Content-Language", lang.String())
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.Render(status, problemRender{body: body})
+	c.Abort()
+}
+
+// FromError mengubah error apa pun menjadi *apperr.Error.
+// Error model (transisi status, readiness) diterjemahkan beserta datanya.
+func FromError(err error) *apperr.Error {
+	var (
+		domainErr     *apperr.Error
+		transitionErr *models.StatusTransitionError
+		roleErr       *models.StatusRoleError
+		readinessErr  *models.ReadinessError
+	)
+	switch {
+	case errors.As(err, &domainErr):
+		return domainErr
+	case errors.As(err, &transitionErr):
+		return repository.ErrInvalidTransition.Wrap(err).
+			With("currentStatus", transitionErr.From).
+			With("requestedStatus", transitionErr.To).
+			With("allowedNext", transitionErr.Allowed)
+	case errors.As(err, &roleErr):
+		return repository.ErrTransitionRole.Wrap(err).
+			With("currentStatus", roleErr.From).
+			With("requestedStatus", roleErr.To).
+			With("allowedRoles", roleErr.Roles)
+	case errors.As(err, &readinessErr):
+		return repository.ErrCourseNotReady.Wrap(err).With("readiness", readinessErr.Report)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound.Wrap(err)
+	default:
+		return ErrInternal.Wrap(err)
+	}
+}
+
+// StatusOf memetakan Kind ke status HTTP
+func StatusOf(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindInvalidInput:
+		return http.StatusBadRequest
+	case apperr.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict, apperr.KindInvalidTransition:
+		return http.StatusConflict
+	case apperr.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case apperr.KindPayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperr.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case apperr.KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// problemRender menulis JSON dengan Content-Type application/problem+json
+type problemRender struct {
+	body gin.H
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.Marshal(r.body)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+}
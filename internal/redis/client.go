@@ -2,38 +2,38 @@ package redis
 
 import (
 	"context"
-	"fmt"
-	"os"
+	"crypto/tls"
+	"log/slog"
 
 	"github.com/redis/go-redis/v9"
-)
 
-var (
-	Client *redis.Client
-	Ctx    = context.Background()
+	"github.com/wtppaul/course-service/internal/config"
+	"github.com/wtppaul/course-service/internal/logging"
 )
 
-// InitRedis initializes the Redis client
-func InitRedis() {
-	host := os.Getenv("REDIS_HOST")
-	// if host == "" {
-	// 	host = "localhost"
-	// }
-	port := os.Getenv("REDIS_PORT")
-	// if port == "" {
-	// 	port = "6379"
-	// }
-
-	addr := fmt.Sprintf("%s:%s", host, port)
-	Client = redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: "", // isi jika pakai password
-		DB:       0,
-	})
+// InitRedis membuat client Redis dan memastikan server bisa dijangkau
+func InitRedis(cfg config.RedisConfig) *redis.Client {
+	opts := &redis.Options{
+		Addr:         cfg.Addr(),
+		Username:     cfg.Username,
+		Password:     cfg.Password.Value(),
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: cfg.Host}
+	}
+	client := redis.NewClient(opts)
 
-	if err := Client.Ping(Ctx).Err(); err != nil {
-		panic(fmt.Sprintf("❌ Failed to connect to Redis: %v", err))
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		logging.Fatal("failed to connect to redis", "error", err, "addr", cfg.Addr())
 	}
 
-	fmt.Println("✅ Redis connected at", addr)
+	slog.Info("redis connected", "addr", cfg.Addr(), "tls", cfg.TLS)
+	return client
 }
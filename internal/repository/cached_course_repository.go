@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+const cacheKeyPrefix = "course-service:cache"
+
+// cacheLoadTimeout membatasi load bersama (singleflight) ke DB. Load tidak
+// ikut batal bersama request pemicunya, karena request lain menunggu hasilnya.
+const cacheLoadTimeout = 10 * time.Second
+
+// cacheGenerationKeys adalah counter yang dinaikkan setiap invalidasi;
+// hasil load hanya disimpan jika semuanya tidak berubah selama load
+var cacheGenerationKeys = []string{cacheKeyPrefix + ":gen", cacheKeyPrefix + ":published:gen"}
+
+// setIfGenerationScript: SET KEYS[1] hanya jika setiap generasi (KEYS[2..])
+// masih sama dengan nilai yang dibaca sebelum load (ARGV[3..]).
+// ARGV[1] = nilai, ARGV[2] = TTL (ms). Atomik terhadap invalidasi.
+var setIfGenerationScript = redis.NewScript(`
+for i = 2, #KEYS do
+	if (redis.call('GET', KEYS[i]) or '0') ~= ARGV[i + 1] then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// cachedCourseRepository adalah decorator read-through di atas ICourseRepository.
+// Semua method yang tidak di-override diteruskan apa adanya (via embedding).
+//
+// Skema key:
+//   {prefix}:gen                         -> generasi global (INCR = invalidasi semua)
+//   {prefix}:v{gen}:course:id:{id}       -> detail course (GetCourseDetails)
+//   {prefix}:v{gen}:course:slug:{slug}   -> detail course (GetCourseBySlug)
+//   {prefix}:published:gen               -> generasi listing publik
+//   {prefix}:v{gen}:published:v{lgen}:{page}:{limit}
+type cachedCourseRepository struct {
+	ICourseRepository
+	client *redis.Client
+	ttl    time.Duration
+	group  singleflight.Group // Stampede protection (per instance)
+}
+
+// NewCachedCourseRepository membungkus repo dengan cache Redis
+func NewCachedCourseRepository(repo ICourseRepository, client *redis.Client, ttl time.Duration) ICourseRepository {
+	return &cachedCourseRepository{
+		ICourseRepository: repo,
+		client:            client,
+		ttl:               ttl,
+	}
+}
+
+// --- Helper cache ---
+
+// generation membaca counter generasi; 0 jika belum ada atau Redis error
+func (r *cachedCourseRepository) generation(ctx context.Context, key string) int64 {
+	gen, err := r.client.Get(ctx, key).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		logging.FromContext(ctx).WarnContext(ctx, "cache: failed to read", "key", key, "error", err)
+	}
+	return gen
+}
+
+func (r *cachedCourseRepository) courseIDKey(ctx context.Context, courseID uuid.UUID) string {
+	return fmt.Sprintf("%s:v%d:course:id:%s", cacheKeyPrefix, r.generation(ctx, cacheKeyPrefix+":gen"), courseID)
+}
+
+func (r *cachedCourseRepository) courseSlugKey(ctx context.Context, slug string) string {
+	return fmt.Sprintf("%s:v%d:course:slug:%s", cacheKeyPrefix, r.generation(ctx, cacheKeyPrefix+":gen"), slug)
+}
+
+func (r *cachedCourseRepository) publishedKey(ctx context.Context, page, limit int) string {
+	return fmt.Sprintf("%s:v%d:published:v%d:%d:%d", cacheKeyPrefix,
+		r.generation(ctx, cacheKeyPrefix+":gen"),
+		r.generation(ctx, cacheKeyPrefix+":published:gen"),
+		page, limit)
+}
+
+// jitteredTTL menambah variasi acak (±10%) agar key tidak kedaluwarsa bersamaan
+func (r *cachedCourseRepository) jitteredTTL() time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(r.ttl)/5+1)) - r.ttl/10
+	return r.ttl + jitter
+}
+
+// snapshotGenerations membaca semua counter generasi sebelum load.
+// ok = false jika Redis error (hasil load tidak disimpan).
+func (r *cachedCourseRepository) snapshotGenerations(ctx context.Context) ([]string, bool) {
+	values, err := r.client.MGet(ctx, cacheGenerationKeys...).Result()
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "cache: failed to read generations", "error", err)
+		return nil, false
+	}
+	gens := make([]string, len(values))
+	for i, v := range values {
+		gens[i] = "0" // Counter yang belum ada = 0 (sama seperti di script)
+		if s, ok := v.(string); ok {
+			gens[i] = s
+		}
+	}
+	return gens, true
+}
+
+// readThrough membaca 'key' dari Redis; jika miss, memanggil 'load' sekali saja
+// (request lain untuk key yang sama menunggu hasil yang sama), lalu menyimpan hasilnya.
+// Jika Redis bermasalah, langsung jatuh ke 'load' (DB tetap jadi sumber kebenaran).
+// Di dalam UnitOfWork cache dilewati: transaksi harus melihat tulisannya sendiri.
+//
+// Hasil load hanya disimpan jika tidak ada invalidasi selama load; tanpa cek
+// ini, load yang membaca data lama bisa menimpa Del dari writer yang commit
+// di tengah-tengah, dan data basi bertahan sampai TTL habis.
+func readThrough[T any](ctx context.Context, r *cachedCourseRepository, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	if inUnitOfWork(ctx) {
+		return load(ctx)
+	}
+
+	var cached T
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err == nil {
+		if jsonErr := json.Unmarshal(data, &cached); jsonErr == nil {
+			return cached, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		logging.FromContext(ctx).WarnContext(ctx, "cache: failed to get", "key", key, "error", err)
+	}
+
+	v, err, _ := r.group.Do(key, func() (interface{}, error) {
+		// Load dipakai bersama: jangan ikut batal jika request pemicunya selesai
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheLoadTimeout)
+		defer cancel()
+
+		// 1. Generasi dibaca SEBELUM load
+		gens, cacheable := r.snapshotGenerations(loadCtx)
+
+		// 2. Load dari DB
+		value, err := load(loadCtx)
+		if err != nil {
+			return value, err // Error (termasuk not found) tidak di-cache
+		}
+
+		// 3. Simpan hanya jika generasi belum berubah (compare-and-set di Redis)
+		if data, err := json.Marshal(value); err == nil && cacheable {
+			if err := r.setIfGeneration(loadCtx, key, data, gens); err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "cache: failed to set", "key", key, "error", err)
+			}
+		}
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// setIfGeneration menyimpan 'data' di 'key' jika generasi masih 'gens'
+func (r *cachedCourseRepository) setIfGeneration(ctx context.Context, key string, data []byte, gens []string) error {
+	keys := append([]string{key}, cacheGenerationKeys...)
+	args := append([]interface{}{data, r.jitteredTTL().Milliseconds()}, stringArgs(gens)...)
+	return setIfGenerationScript.Run(ctx, r.client, keys, args...).Err()
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// invalidateCourse menaikkan generasi listing publik dan menghapus entry
+// detail course (by id & slug) dalam satu MULTI, sehingga load yang sedang
+// berjalan tidak bisa menyimpan data lama di antara keduanya. Di dalam
+// UnitOfWork, invalidasi ditunda sampai commit (agar request lain tidak
+// meng-cache data lama).
+func (r *cachedCourseRepository) invalidateCourse(ctx context.Context, courseID uuid.UUID, slug string) {
+	if slug == "" {
+		if course, err := r.ICourseRepository.GetCourseByID(ctx, courseID); err == nil {
+			slug = course.Slug
+		}
+	}
+
+	afterCommit(ctx, func() {
+		keys := []string{r.courseIDKey(ctx, courseID)}
+		if slug != "" {
+			keys = append(keys, r.courseSlugKey(ctx, slug))
+		}
+		_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Incr(ctx, cacheKeyPrefix+":published:gen")
+			pipe.Del(ctx, keys...)
+			return nil
+		})
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "cache: failed to invalidate course", "course_id", courseID, "error", err)
+		}
+	})
+}
+
+// invalidateChapter meng-invalidasi course pemilik chapter
+func (r *cachedCourseRepository) invalidateChapter(ctx context.Context, chapterID uuid.UUID) {
+	if chapter, err := r.ICourseRepository.GetChapterByID(ctx, chapterID); err == nil {
+		r.invalidateCourse(ctx, chapter.CourseID, "")
+	}
+}
+
+// invalidateAll menaikkan generasi global (dipakai saat kategori/tag berubah,
+// karena satu perubahan bisa menyentuh banyak course sekaligus)
+func (r *cachedCourseRepository) invalidateAll(ctx context.Context) {
+	afterCommit(ctx, func() {
+		if err := r.client.Incr(ctx, cacheKeyPrefix+":gen").Err(); err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "cache: failed to bump global generation", "error", err)
+		}
+	})
+}
+
+// --- Read (cached) ---
+
+func (r *cachedCourseRepository) GetCourseBySlug(ctx context.Context, slug string) (*models.Course, error) {
+	return readThrough(ctx, r, r.courseSlugKey(ctx, slug), func(ctx context.Context) (*models.Course, error) {
+		return r.ICourseRepository.GetCourseBySlug(ctx, slug)
+	})
+}
+
+func (r *cachedCourseRepository) GetCourseDetails(ctx context.Context, courseID uuid.UUID) (*models.Course, error) {
+	return readThrough(ctx, r, r.courseIDKey(ctx, courseID), func(ctx context.Context) (*models.Course, error) {
+		return r.ICourseRepository.GetCourseDetails(ctx, courseID)
+	})
+}
+
+func (r *cachedCourseRepository) GetPublishedCourses(ctx context.Context, page, limit int) ([]*models.Course, error) {
+	return readThrough(ctx, r, r.publishedKey(ctx, page, limit), func(ctx context.Context) ([]*models.Course, error) {
+		return r.ICourseRepository.GetPublishedCourses(ctx, page, limit)
+	})
+}
+
+// --- Write (invalidate) ---
+
+func (r *cachedCourseRepository) UpdateCourse(ctx context.Context, courseID uuid.UUID, input UpdateCourseInput) (*models.Course, error) {
+	course, err := r.ICourseRepository.UpdateCourse(ctx, courseID, input)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, course.Slug)
+	}
+	return course, err
+}
+
+func (r *cachedCourseRepository) UpdateCourseTags(ctx context.Context, courseID uuid.UUID, tagIDs []uuid.UUID) error {
+	err := r.ICourseRepository.UpdateCourseTags(ctx, courseID, tagIDs)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, "")
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) UpdateCourseCategories(ctx context.Context, courseID uuid.UUID, categoryIDs []uuid.UUID) error {
+	err := r.ICourseRepository.UpdateCourseCategories(ctx, courseID, categoryIDs)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, "")
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) UpdateCourseStatus(ctx context.Context, courseID uuid.UUID, change CourseStatusChange, check StatusCheckFunc) error {
+	err := r.ICourseRepository.UpdateCourseStatus(ctx, courseID, change, check)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, "")
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) CreateChapter(ctx context.Context, chapter *models.Chapter) error {
+	err := r.ICourseRepository.CreateChapter(ctx, chapter)
+	if err == nil {
+		r.invalidateCourse(ctx, chapter.CourseID, "")
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) UpdateChapter(ctx context.Context, chapter *models.Chapter) (*models.Chapter, error) {
+	updated, err := r.ICourseRepository.UpdateChapter(ctx, chapter)
+	if err == nil {
+		r.invalidateCourse(ctx, chapter.CourseID, "")
+	}
+	return updated, err
+}
+
+func (r *cachedCourseRepository) ReorderChapters(ctx context.Context, courseID uuid.UUID, version int64, updates []ChapterReorderInput) (int64, error) {
+	newVersion, err := r.ICourseRepository.ReorderChapters(ctx, courseID, version, updates)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, "")
+	}
+	return newVersion, err
+}
+
+func (r *cachedCourseRepository) DeleteChapter(ctx context.Context, courseID uuid.UUID, chapterID uuid.UUID) error {
+	err := r.ICourseRepository.DeleteChapter(ctx, courseID, chapterID)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, "")
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) SaveCurriculum(ctx context.Context, courseID uuid.UUID, version int64, chapters []CurriculumChapterInput) (*CurriculumSaveResult, error) {
+	result, err := r.ICourseRepository.SaveCurriculum(ctx, courseID, version, chapters)
+	if err == nil {
+		r.invalidateCourse(ctx, courseID, "")
+	}
+	return result, err
+}
+
+func (r *cachedCourseRepository) CreateLesson(ctx context.Context, lesson *models.Lesson) error {
+	err := r.ICourseRepository.CreateLesson(ctx, lesson)
+	if err == nil {
+		r.invalidateChapter(ctx, lesson.ChapterID)
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) UpdateLesson(ctx context.Context, lesson *models.Lesson) (*models.Lesson, error) {
+	updated, err := r.ICourseRepository.UpdateLesson(ctx, lesson)
+	if err == nil {
+		r.invalidateChapter(ctx, lesson.ChapterID)
+	}
+	return updated, err
+}
+
+func (r *cachedCourseRepository) DeleteLesson(ctx context.Context, lessonID uuid.UUID) error {
+	// Ambil chapter sebelum lesson dihapus
+	lesson, err := r.ICourseRepository.GetLessonByID(ctx, lessonID)
+	if err != nil {
+		return err
+	}
+	err = r.ICourseRepository.DeleteLesson(ctx, lessonID)
+	if err == nil {
+		r.invalidateCourse(ctx, lesson.CourseID, "")
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) ReorderLessons(ctx context.Context, chapterID uuid.UUID, version int64, updates []LessonReorderInput) (int64, error) {
+	newVersion, err := r.ICourseRepository.ReorderLessons(ctx, chapterID, version, updates)
+	if err == nil {
+		r.invalidateChapter(ctx, chapterID)
+	}
+	return newVersion, err
+}
+
+func (r *cachedCourseRepository) MoveLesson(ctx context.Context, lessonID, targetChapterID uuid.UUID, position int, version int64) (*models.Lesson, int64, error) {
+	lesson, newVersion, err := r.ICourseRepository.MoveLesson(ctx, lessonID, targetChapterID, position, version)
+	if err == nil {
+		r.invalidateCourse(ctx, lesson.CourseID, "")
+	}
+	return lesson, newVersion, err
+}
+
+func (r *cachedCourseRepository) UpdateCategory(ctx context.Context, category *models.Category) (*models.Category, error) {
+	updated, err := r.ICourseRepository.UpdateCategory(ctx, category)
+	if err == nil {
+		r.invalidateAll(ctx)
+	}
+	return updated, err
+}
+
+func (r *cachedCourseRepository) MoveCategory(ctx context.Context, categoryID uuid.UUID, newParentID *uuid.UUID) error {
+	err := r.ICourseRepository.MoveCategory(ctx, categoryID, newParentID)
+	if err == nil {
+		r.invalidateAll(ctx)
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	err := r.ICourseRepository.DeleteCategory(ctx, categoryID)
+	if err == nil {
+		r.invalidateAll(ctx)
+	}
+	return err
+}
+
+func (r *cachedCourseRepository) RenameTag(ctx context.Context, tag *models.Tag) (*models.Tag, error) {
+	updated, err := r.ICourseRepository.RenameTag(ctx, tag)
+	if err == nil {
+		r.invalidateAll(ctx)
+	}
+	return updated, err
+}
+
+func (r *cachedCourseRepository) MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) (int64, error) {
+	moved, err := r.ICourseRepository.MergeTags(ctx, sourceID, targetID)
+	if err == nil {
+		r.invalidateAll(ctx)
+	}
+	return moved, err
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// newTestCache membuat decorator cache di atas miniredis (tanpa repo di bawahnya)
+func newTestCache(t *testing.T) (*cachedCourseRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &cachedCourseRepository{client: client, ttl: time.Minute}, mr
+}
+
+func TestReadThroughSkipsSetAfterInvalidation(t *testing.T) {
+	courseID := uuid.New()
+
+	tests := []struct {
+		name       string
+		duringLoad func(ctx context.Context, r *cachedCourseRepository) // Writer yang commit di tengah load
+		wantCached bool
+	}{
+		{name: "no concurrent write", duringLoad: func(context.Context, *cachedCourseRepository) {}, wantCached: true},
+		{name: "course invalidated during load", duringLoad: func(ctx context.Context, r *cachedCourseRepository) {
+			r.invalidateCourse(ctx, courseID, "go-dasar")
+		}},
+		{name: "global invalidation during load", duringLoad: func(ctx context.Context, r *cachedCourseRepository) {
+			r.invalidateAll(ctx)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mr := newTestCache(t)
+			ctx := context.Background()
+			key := r.courseIDKey(ctx, courseID)
+
+			course, err := readThrough(ctx, r, key, func(ctx context.Context) (*models.Course, error) {
+				tt.duringLoad(ctx, r)
+				return &models.Course{ID: courseID, Title: "Judul lama"}, nil
+			})
+			if err != nil || course.ID != courseID {
+				t.Fatalf("readThrough = %v, %v; want the loaded course", course, err)
+			}
+			if got := mr.Exists(key); got != tt.wantCached {
+				t.Fatalf("cached = %v, want %v", got, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestReadThroughSharedLoadOutlivesCaller(t *testing.T) {
+	r, mr := newTestCache(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Request pemicu sudah selesai/batal
+
+	key := r.courseIDKey(context.Background(), uuid.New())
+	_, err := readThrough(ctx, r, key, func(ctx context.Context) (*models.Course, error) {
+		if err := ctx.Err(); err != nil {
+			t.Errorf("load ctx err = %v, want a live context", err)
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("load ctx has no deadline")
+		}
+		return &models.Course{}, nil
+	})
+	if err != nil {
+		t.Fatalf("readThrough: %v", err)
+	}
+	if !mr.Exists(key) {
+		t.Fatal("result of the shared load was not cached")
+	}
+}
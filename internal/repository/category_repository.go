@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// ✅
+// CreateCategory membuat kategori baru
+// ('category.Slug' harus sudah di-set oleh handler)
+func (r *courseRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	return duplicate(r.db.WithContext(ctx).Create(category).Error, ErrCategoryNameTaken)
+}
+
+// GetCategoryByID mengambil satu kategori
+func (r *courseRepository) GetCategoryByID(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	err := r.db.WithContext(ctx).Where("id = ?", categoryID).First(&category).Error
+	if err != nil {
+		return nil, notFound(err, ErrCategoryNotFound)
+	}
+	return &category, nil
+}
+
+// UpdateCategory memperbarui nama kategori
+// (ParentID hanya boleh diubah lewat MoveCategory agar dicek siklusnya)
+func (r *courseRepository) UpdateCategory(ctx context.Context, category *models.Category) (*models.Category, error) {
+	err := r.db.WithContext(ctx).
+		Model(category).
+		Select("name").
+		Updates(category).Error
+	if err != nil {
+		return nil, duplicate(err, ErrCategoryNameTaken)
+	}
+	return category, nil
+}
+
+// MoveCategory memindahkan kategori ke parent baru (nil = jadi root)
+func (r *courseRepository) MoveCategory(ctx context.Context, categoryID uuid.UUID, newParentID *uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Serialisasi semua operasi move, agar dua move yang bersamaan
+		//    (A ke bawah B dan B ke bawah A) tidak bisa membuat siklus
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('categories_move'))").Error; err != nil {
+			return err
+		}
+
+		// 2. Pastikan kategori ada
+		var category models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", categoryID).First(&category).Error; err != nil {
+			return notFound(err, ErrCategoryNotFound)
+		}
+
+		// 3. Deteksi siklus: parent baru tidak boleh kategori ini sendiri
+		//    atau salah satu turunannya (= kategori ini ada di leluhur parent baru)
+		if newParentID != nil {
+			if *newParentID == categoryID {
+				return ErrCategoryCycle
+			}
+
+			var parent models.Category
+			if err := tx.Where("id = ?", *newParentID).First(&parent).Error; err != nil {
+				return notFound(err, ErrCategoryNotFound.With("categoryId", *newParentID))
+			}
+
+			var cycle bool
+			err := tx.Raw(`
+				WITH RECURSIVE ancestors AS (
+					SELECT id, parent_id FROM categories WHERE id = @parent
+					UNION
+					SELECT c.id, c.parent_id FROM categories c
+					JOIN ancestors a ON a.parent_id = c.id
+				)
+				SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = @id)`,
+				map[string]interface{}{"parent": *newParentID, "id": categoryID},
+			).Scan(&cycle).Error
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return ErrCategoryCycle
+			}
+		}
+
+		// 4. Simpan parent baru
+		return tx.Model(&models.Category{}).
+			Where("id = ?", categoryID).
+			Update("parent_id", newParentID).Error
+	})
+}
+
+// DeleteCategory menghapus kategori beserta relasinya ke course & kupon.
+// Kategori yang masih punya anak harus dipindah/dihapus anaknya dulu.
+func (r *courseRepository) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Pastikan kategori ada
+		var category models.Category
+		if err := tx.Where("id = ?", categoryID).First(&category).Error; err != nil {
+			return notFound(err, ErrCategoryNotFound)
+		}
+
+		// 2. Tolak jika masih punya anak
+		var children int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", categoryID).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrCategoryHasChildren
+		}
+
+		// 3. Hapus relasi many-to-many
+		if err := tx.Exec("DELETE FROM course_categories WHERE category_id = ?", categoryID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM coupon_categories WHERE category_id = ?", categoryID).Error; err != nil {
+			return err
+		}
+
+		// 4. Hapus kategori itu sendiri
+		return tx.Where("id = ?", categoryID).Delete(&models.Category{}).Error
+	})
+}
+
+// GetCategoryTree mengambil semua kategori sebagai pohon bersarang
+// (hanya root di level teratas, anak-anak di 'Children')
+func (r *courseRepository) GetCategoryTree(ctx context.Context) ([]models.Category, error) {
+	var categories []*models.Category
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+// buildCategoryTree menyusun daftar kategori datar menjadi pohon. Urutan
+// saudara mengikuti urutan input. Kategori yang parent-nya tidak ada di
+// input tidak ikut (tidak bisa dicapai dari root), dan siklus di data
+// tidak membuat rekursi tanpa akhir.
+func buildCategoryTree(categories []*models.Category) []models.Category {
+	// Kelompokkan berdasarkan parent (uuid.Nil = root)
+	byParent := make(map[uuid.UUID][]*models.Category)
+	for _, cat := range categories {
+		parentID := uuid.Nil
+		if cat.ParentID != nil {
+			parentID = *cat.ParentID
+		}
+		byParent[parentID] = append(byParent[parentID], cat)
+	}
+
+	visited := make(map[uuid.UUID]bool, len(categories))
+	var build func(parentID uuid.UUID) []models.Category
+	build = func(parentID uuid.UUID) []models.Category {
+		nodes := make([]models.Category, 0, len(byParent[parentID]))
+		for _, cat := range byParent[parentID] {
+			if visited[cat.ID] {
+				continue
+			}
+			visited[cat.ID] = true
+			node := *cat
+			node.Children = build(cat.ID)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build(uuid.Nil)
+}
+
+// IsCategorySlugInUse mengecek keunikan slug kategori
+func (r *courseRepository) IsCategorySlugInUse(ctx context.Context, slug string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Category{}).Where("slug = ?", slug).Count(&count).Error
+	if err != nil {
+		return true, err
+	}
+	return count > 0, nil
+}
+
+// UpdateCourseCategories mengganti semua kategori sebuah course
+// (cara kerjanya sama seperti UpdateCourseTags)
+func (r *courseRepository) UpdateCourseCategories(ctx context.Context, courseID uuid.UUID, categoryIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Pastikan course ada
+		var course models.Course
+		if err := tx.Select("id").Where("id = ?", courseID).First(&course).Error; err != nil {
+			return notFound(err, ErrCourseNotFound)
+		}
+
+		// 2. Pastikan semua kategori ada, agar GORM tidak membuat
+		//    baris kategori kosong untuk ID yang tidak dikenal
+		var categories []models.Category
+		if len(categoryIDs) > 0 {
+			if err := tx.Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+				return err
+			}
+			if len(categories) != len(uniqueIDs(categoryIDs)) {
+				return ErrCategoryNotFound
+			}
+		}
+
+		// 3. Ganti semua relasi di tabel 'course_categories'
+		return tx.Model(&course).Association("Categories").Replace(categories)
+	})
+}
+
+// uniqueIDs membuang UUID duplikat dari input
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// renderTree menulis pohon sebagai "A(B,C(D)),E" agar mudah dibandingkan
+func renderTree(nodes []models.Category) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.Name
+		if len(node.Children) > 0 {
+			parts[i] += "(" + renderTree(node.Children) + ")"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	// category membuat kategori 'name' di bawah 'parent' (nil = root)
+	category := func(name string, parent *models.Category) *models.Category {
+		cat := &models.Category{ID: uuid.New(), Name: name}
+		if parent != nil {
+			cat.ParentID = &parent.ID
+		}
+		return cat
+	}
+
+	backend := category("Backend", nil)
+	golang := category("Go", backend)
+	gin := category("Gin", golang)
+	rust := category("Rust", backend)
+	frontend := category("Frontend", nil)
+	orphan := category("Orphan", &models.Category{ID: uuid.New()})
+
+	loopA := category("Loop A", nil)
+	loopB := category("Loop B", loopA)
+	loopA.ParentID = &loopB.ID // Siklus di data (tanpa root)
+
+	tests := []struct {
+		name       string
+		categories []*models.Category
+		want       string
+	}{
+		{name: "empty", want: ""},
+		{name: "roots only keep input order", categories: []*models.Category{frontend, backend}, want: "Frontend,Backend"},
+		{name: "nested levels", categories: []*models.Category{backend, frontend, gin, golang, rust}, want: "Backend(Go(Gin),Rust),Frontend"},
+		{name: "children before parent in input", categories: []*models.Category{gin, golang, backend}, want: "Backend(Go(Gin))"},
+		{name: "orphan is not reachable", categories: []*models.Category{backend, orphan}, want: "Backend"},
+		{name: "cycle in data terminates", categories: []*models.Category{backend, loopA, loopB}, want: "Backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := buildCategoryTree(tt.categories)
+			if tree == nil {
+				t.Fatal("tree is nil, want an empty slice")
+			}
+			if got := renderTree(tree); got != tt.want {
+				t.Fatalf("tree = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveCategoryRejectsCycles(t *testing.T) {
+	categoryID, parentID := uuid.New(), uuid.New()
+
+	// expectMove mengharapkan lock & SELECT kategori yang dipindah
+	expectMove := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT \* FROM "categories" WHERE id = .* FOR UPDATE`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(categoryID))
+	}
+	// expectAncestors mengharapkan parent ditemukan lalu cek leluhur -> 'cycle'
+	expectAncestors := func(mock sqlmock.Sqlmock, cycle bool) {
+		mock.ExpectQuery(`SELECT \* FROM "categories" WHERE id = `).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(parentID))
+		mock.ExpectQuery(`WITH RECURSIVE ancestors`).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(cycle))
+	}
+
+	tests := []struct {
+		name      string
+		newParent *uuid.UUID
+		expect    func(mock sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "under itself", newParent: &categoryID,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectMove(mock)
+				mock.ExpectRollback()
+			},
+			wantErr: ErrCategoryCycle,
+		},
+		{
+			name: "under a descendant", newParent: &parentID,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectMove(mock)
+				expectAncestors(mock, true)
+				mock.ExpectRollback()
+			},
+			wantErr: ErrCategoryCycle,
+		},
+		{
+			name: "unknown parent", newParent: &parentID,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectMove(mock)
+				mock.ExpectQuery(`SELECT \* FROM "categories" WHERE id = `).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrCategoryNotFound,
+		},
+		{
+			name: "under an unrelated category", newParent: &parentID,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectMove(mock)
+				expectAncestors(mock, false)
+				mock.ExpectExec(`UPDATE "categories" SET "parent_id"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "to root skips the cycle check",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectMove(mock)
+				mock.ExpectExec(`UPDATE "categories" SET "parent_id"`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			tt.expect(mock)
+
+			err := NewCourseRepository(db).MoveCategory(context.Background(), categoryID, tt.newParent)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"time"
 	"errors"
-	"fmt" 
+	"slices"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"github.com/google/uuid"
 	"github.com/wtppaul/course-service/internal/models"
 )
@@ -23,13 +25,42 @@ type UpdateCourseInput struct {
 	IsFree      bool                 `json:"isFree"`
 	License     models.CourseLicense `json:"license"`
 	// (Tambahkan CategoryIDs, TagIDs jika Anda ingin mengizinkan pembaruan di sini)
+
+	// Version adalah versi yang dilihat client (dari If-Match).
+	// 0 = tanpa pengecekan (If-Match: *)
+	Version int64 `json:"-"`
+}
+
+// ChapterReorderInput adalah satu item dalam payload reorder chapter
+type ChapterReorderInput struct {
+	ID    uuid.UUID `json:"id" binding:"required"`
+	Order int       `json:"order"`
+}
+
+// LessonReorderInput adalah satu item dalam payload reorder lesson
+type LessonReorderInput struct {
+	ID    uuid.UUID `json:"id" binding:"required"`
+	Order int       `json:"order"`
+}
+
+// CourseStatusChange adalah permintaan perubahan status beserta
+// informasi yang dicatat ke riwayat (course_status_events)
+type CourseStatusChange struct {
+	Status      models.CourseStatus
+	ActorAuthID string
+	Reason      string
 }
 
+// StatusCheckFunc dipanggil dengan status saat ini (di dalam transaksi)
+// sebelum status baru disimpan. Kembalikan error untuk membatalkan.
+type StatusCheckFunc func(current models.CourseStatus) error
+
 type CourseFilters struct {
 	Status    		[]string // ["PUBLISHED", "APPROVED", .....])
 	Level     		[]string 
 	CategorySlugs []string 
 	TagSlugs      []string 
+	Query         string // Full-text search (lihat SearchCourses)
 	TeacherID 		uuid.UUID
 	Page      		int
 	Limit     		int
@@ -41,33 +72,72 @@ type ICourseRepository interface {
 	CreateCourse(ctx context.Context, course *models.Course) error
 	UpdateCourse(ctx context.Context, courseID uuid.UUID, input UpdateCourseInput) (*models.Course, error)
 	UpdateCourseTags(ctx context.Context, courseID uuid.UUID, tagIDs []uuid.UUID) error
-	UpdateCourseStatus(ctx context.Context, courseID uuid.UUID, newStatus models.CourseStatus) error
+	UpdateCourseStatus(ctx context.Context, courseID uuid.UUID, change CourseStatusChange, check StatusCheckFunc) error
+	GetCourseStatusHistory(ctx context.Context, courseID uuid.UUID) ([]*models.CourseStatusEvent, error)
 	
 	// --- FUNGSI CHAPTER ---
 	CreateChapter(ctx context.Context, chapter *models.Chapter) error
 	GetChapterByID(ctx context.Context, chapterID uuid.UUID) (*models.Chapter, error)    
 	UpdateChapter(ctx context.Context, chapter *models.Chapter) (*models.Chapter, error)
-	ReorderChapters(ctx context.Context, courseID uuid.UUID, updates []ChapterReorderInput) error // ✅ BARU
+	ReorderChapters(ctx context.Context, courseID uuid.UUID, version int64, updates []ChapterReorderInput) (int64, error) // ✅ BARU
 	DeleteChapter(ctx context.Context, courseID uuid.UUID, chapterID uuid.UUID) error // ✅ BARU
+	SaveCurriculum(ctx context.Context, courseID uuid.UUID, version int64, chapters []CurriculumChapterInput) (*CurriculumSaveResult, error)
 
 		// --- FUNGSI LESSON ---
 	CreateLesson(ctx context.Context, lesson *models.Lesson) error
 	UpdateLesson(ctx context.Context, lesson *models.Lesson) (*models.Lesson, error) // ✅ BARU
 	GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error)    // ✅ BARU
+	DeleteLesson(ctx context.Context, lessonID uuid.UUID) error
+	ReorderLessons(ctx context.Context, chapterID uuid.UUID, version int64, updates []LessonReorderInput) (int64, error)
+	MoveLesson(ctx context.Context, lessonID, targetChapterID uuid.UUID, position int, version int64) (*models.Lesson, int64, error)
 
 	// Operasi untuk Publik/User (via BFF)
 	GetCourseBySlug(ctx context.Context, slug string) (*models.Course, error) // Ini yang kita perbaiki
 	GetPublishedCourses(ctx context.Context, page, limit int) ([]*models.Course, error)
 	GetCourseDetails(ctx context.Context, courseID uuid.UUID) (*models.Course, error) // Mirip dengan Slug, tapi by ID
+	GetCourseByID(ctx context.Context, courseID uuid.UUID) (*models.Course, error) // Tanpa preload (ringan)
 	GetCoursesByTeacherID(ctx context.Context, teacherID uuid.UUID) ([]*models.Course, error)
 	GetCourses(ctx context.Context, filters CourseFilters) ([]*models.Course, int64, error)
+	SearchCourses(ctx context.Context, filters CourseFilters) (*CourseSearchResult, error)
 	
 	// Operasi untuk Pricing (dipanggil oleh Payment-service)
-	FindValidCoupon(ctx context.Context, code string) (*models.Coupon, error)
+	FindValidCoupon(ctx context.Context, code string, courseID uuid.UUID) (*models.Coupon, error)
+	RedeemCoupon(ctx context.Context, code string, courseID uuid.UUID, authID string) (*models.Coupon, *models.CouponRedemption, error)
+	ReleaseCoupon(ctx context.Context, redemptionID uuid.UUID) error
 	GetActiveSalesForCourse(ctx context.Context, courseID uuid.UUID) ([]*models.Sale, error)
 
+	// --- FUNGSI CATEGORY ---
+	CreateCategory(ctx context.Context, category *models.Category) error
+	GetCategoryByID(ctx context.Context, categoryID uuid.UUID) (*models.Category, error)
+	UpdateCategory(ctx context.Context, category *models.Category) (*models.Category, error)
+	MoveCategory(ctx context.Context, categoryID uuid.UUID, newParentID *uuid.UUID) error
+	DeleteCategory(ctx context.Context, categoryID uuid.UUID) error
+	GetCategoryTree(ctx context.Context) ([]models.Category, error)
+	IsCategorySlugInUse(ctx context.Context, slug string) (bool, error)
+	UpdateCourseCategories(ctx context.Context, courseID uuid.UUID, categoryIDs []uuid.UUID) error
+
+	// --- FUNGSI ENROLLMENT (dipanggil oleh Payment-service & BFF) ---
+	GrantEnrollment(ctx context.Context, input GrantEnrollmentInput) (*models.Enrollment, bool, error)
+	RevokeEnrollment(ctx context.Context, authID string, courseID uuid.UUID, reason string) (*models.Enrollment, error)
+	GetEnrollment(ctx context.Context, authID string, courseID uuid.UUID) (*models.Enrollment, error)
+	IsCourseOwner(ctx context.Context, courseID uuid.UUID, authID string) (bool, error)
+
+	// --- FUNGSI PROGRESS (dipanggil oleh player via BFF) ---
+	UpsertLessonProgress(ctx context.Context, authID string, inputs []LessonProgressInput) ([]*models.LessonProgress, error)
+	GetCourseProgress(ctx context.Context, authID string, courseID uuid.UUID) (*models.CourseProgressSummary, error)
+
+	// --- FUNGSI TAG ---
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	GetTagByID(ctx context.Context, tagID uuid.UUID) (*models.Tag, error)
+	GetTags(ctx context.Context, page, limit int) ([]*TagWithUsage, int64, error)
+	RenameTag(ctx context.Context, tag *models.Tag) (*models.Tag, error)
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]*TagWithUsage, error)
+	MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) (int64, error)
+	IsTagSlugInUse(ctx context.Context, slug string) (bool, error)
+
 	IsSlugInUse(ctx context.Context, slug string) (bool, error)
 	FindOrCreateTeacherByAuthID(ctx context.Context, authID string) (*models.Teacher, error)
+	IsTeacherAuthID(ctx context.Context, teacherID uuid.UUID, authID string) (bool, error)
 }
 
 type courseRepository struct {
@@ -82,51 +152,173 @@ func NewCourseRepository(db *gorm.DB) ICourseRepository {
 
 
 func (r *courseRepository) CreateCourse(ctx context.Context, course *models.Course) error {
-	return r.db.WithContext(ctx).Create(course).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(course).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, models.EventCourseCreated, course.ID, map[string]interface{}{
+			"courseId":  course.ID,
+			"title":     course.Title,
+			"slug":      course.Slug,
+			"teacherId": course.TeacherID,
+			"status":    course.Status,
+		})
+	})
 }
 
 // ✅ 
 func (r *courseRepository) UpdateCourse(ctx context.Context, courseID uuid.UUID, input UpdateCourseInput) (*models.Course, error) {
-	// 1. Ambil kursus yang ada
 	var course models.Course
-	if err := r.db.WithContext(ctx).First(&course, "id = ?", courseID).Error; err != nil {
-		return nil, err // (Akan GORM.ErrRecordNotFound jika tidak ada)
-	}
-
-	// 2. Terapkan pembaruan dari input
-	// (Ini mencegah 'slug', 'teacherId', 'status' di-update secara tidak sengaja)
-	course.Title = input.Title
-	course.Description = input.Description
-	course.Thumbnail = input.Thumbnail
-	course.Price = input.Price
-	course.Level = input.Level
-	course.IsFree = input.IsFree
-	course.License = input.License
-	
-	// 3. Simpan perubahan
-	if err := r.db.WithContext(ctx).Save(&course).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Ambil & kunci kursus yang ada
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&course, "id = ?", courseID).Error; err != nil {
+			return notFound(err, ErrCourseNotFound)
+		}
+		oldPrice, oldIsFree := course.Price, course.IsFree
+
+		// 1b. Tolak jika client mengedit versi yang sudah usang
+		if input.Version != 0 && input.Version != course.Version {
+			return ErrVersionConflict
+		}
+		course.Version++
+
+		// 2. Terapkan pembaruan dari input
+		// (Ini mencegah 'slug', 'teacherId', 'status' di-update secara tidak sengaja)
+		course.Title = input.Title
+		course.Description = input.Description
+		course.Thumbnail = input.Thumbnail
+		course.Price = input.Price
+		course.Level = input.Level
+		course.IsFree = input.IsFree
+		course.License = input.License
+
+		// 3. Simpan perubahan
+		if err := tx.Save(&course).Error; err != nil {
+			return err
+		}
+
+		// 4. Beri tahu service lain (misal Payment) jika harga berubah
+		if course.Price != oldPrice || course.IsFree != oldIsFree {
+			return enqueueEvent(tx, models.EventCourseRepriced, course.ID, map[string]interface{}{
+				"courseId":  course.ID,
+				"oldPrice":  oldPrice,
+				"price":     course.Price,
+				"oldIsFree": oldIsFree,
+				"isFree":    course.IsFree,
+			})
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	
 	return &course, nil
 }
 
-func (r *courseRepository) UpdateCourseStatus(ctx context.Context, courseID uuid.UUID, newStatus models.CourseStatus) error {
-	return r.db.WithContext(ctx).
-		Model(&models.Course{}).
-		Where("id = ?", courseID).
-		Updates(map[string]interface{}{
-			"status":     newStatus,
-			"updated_at": time.Now(),
-		}).Error
+// UpdateCourseStatus mengubah status di dalam transaksi.
+// Baris course dikunci (SELECT ... FOR UPDATE) sehingga 'check' selalu
+// melihat status terkini; dua reviewer yang bersamaan tidak bisa sama-sama menang.
+// Setiap perubahan juga dicatat ke 'course_status_events' di transaksi yang sama.
+func (r *courseRepository) UpdateCourseStatus(ctx context.Context, courseID uuid.UUID, change CourseStatusChange, check StatusCheckFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci baris dan baca status saat ini
+		//    (perubahan kurikulum ikut menunggu, lihat lockCurriculum)
+		var course models.Course
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			Where("id = ?", courseID).
+			First(&course).Error
+		if err != nil {
+			return notFound(err, ErrCourseNotFound)
+		}
+
+		// 2. Validasi transisi terhadap status terkini
+		if check != nil {
+			if err := check(course.Status); err != nil {
+				return err // Rollback
+			}
+		}
+
+		// 3. Simpan status baru
+		now := time.Now()
+		err = tx.Model(&models.Course{}).
+			Where("id = ?", courseID).
+			Updates(map[string]interface{}{
+				"status":     change.Status,
+				"updated_at": now,
+				"version":    gorm.Expr("version + 1"),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// 4. Catat riwayat (append-only)
+		event := &models.CourseStatusEvent{
+			CourseID:    courseID,
+			FromStatus:  course.Status,
+			ToStatus:    change.Status,
+			ActorAuthID: change.ActorAuthID,
+			Reason:      change.Reason,
+			CreatedAt:   now,
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+
+		// 5. Outbox: event perubahan status (+ event khusus saat publish)
+		data := map[string]interface{}{
+			"courseId":   courseID,
+			"fromStatus": course.Status,
+			"toStatus":   change.Status,
+			"actorId":    change.ActorAuthID,
+		}
+		if err := enqueueEvent(tx, models.EventCourseStatusChanged, courseID, data); err != nil {
+			return err
+		}
+		if change.Status == models.StatusPublished {
+			return enqueueEvent(tx, models.EventCoursePublished, courseID, data)
+		}
+		return nil
+	})
+}
+
+// GetCourseStatusHistory mengambil riwayat status, dari yang paling lama
+func (r *courseRepository) GetCourseStatusHistory(ctx context.Context, courseID uuid.UUID) ([]*models.CourseStatusEvent, error) {
+	var events []*models.CourseStatusEvent
+	err := r.db.WithContext(ctx).
+		Where("course_id = ?", courseID).
+		Order("created_at ASC").
+		Find(&events).Error
+	return events, err
 }
 
 func (r *courseRepository) CreateChapter(ctx context.Context, chapter *models.Chapter) error {
-	return r.db.WithContext(ctx).Create(chapter).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchCurriculum(tx, chapter.CourseID); err != nil {
+			return err
+		}
+		if err := tx.Create(chapter).Error; err != nil {
+			return err
+		}
+		return enqueueCurriculumChanged(tx, chapter.CourseID, "chapter.created", chapter.ID)
+	})
 }
 
 func (r *courseRepository) CreateLesson(ctx context.Context, lesson *models.Lesson) error {
-	return r.db.WithContext(ctx).Create(lesson).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		courseID, err := courseIDOfChapter(tx, lesson.ChapterID)
+		if err != nil {
+			return notFound(err, ErrChapterNotFound)
+		}
+		if err := touchCurriculum(tx, courseID); err != nil {
+			return err
+		}
+		if err := tx.Create(lesson).Error; err != nil {
+			return err
+		}
+		return enqueueCurriculumChanged(tx, courseID, "lesson.created", lesson.ID)
+	})
 }
 
 // ✅
@@ -149,7 +341,7 @@ func (r *courseRepository) GetCourseBySlug(ctx context.Context, slug string) (*m
 		First(&course).Error
 	
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, ErrCourseNotFound)
 	}
 	return &course, nil
 }
@@ -188,8 +380,20 @@ func (r *courseRepository) GetCourseDetails(ctx context.Context, courseID uuid.U
 		Preload("Tags").
 		Where("id = ?", courseID).
 		First(&course).Error
-	
-	return &course, err
+	if err != nil {
+		return nil, notFound(err, ErrCourseNotFound)
+	}
+	return &course, nil
+}
+
+// GetCourseByID mengambil baris course saja, tanpa relasi
+func (r *courseRepository) GetCourseByID(ctx context.Context, courseID uuid.UUID) (*models.Course, error) {
+	var course models.Course
+	err := r.db.WithContext(ctx).Where("id = ?", courseID).First(&course).Error
+	if err != nil {
+		return nil, notFound(err, ErrCourseNotFound)
+	}
+	return &course, nil
 }
 
 // ✅ 
@@ -246,19 +450,143 @@ func (r *courseRepository) FindOrCreateTeacherByAuthID(ctx context.Context, auth
 	return nil, err
 }
 
-func (r *courseRepository) FindValidCoupon(ctx context.Context, code string) (*models.Coupon, error) {
+// IsTeacherAuthID mengecek apakah profil teacher 'teacherID' milik 'authID'
+// (tanpa membuat profil teacher baru)
+func (r *courseRepository) IsTeacherAuthID(ctx context.Context, teacherID uuid.UUID, authID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Teacher{}).
+		Where("id = ? AND auth_id = ?", teacherID, authID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindValidCoupon mencari kupon aktif yang berlaku untuk course ini.
+// Kupon tanpa relasi course/kategori berlaku untuk semua course.
+func (r *courseRepository) FindValidCoupon(ctx context.Context, code string, courseID uuid.UUID) (*models.Coupon, error) {
 	var coupon models.Coupon
 	err := r.db.WithContext(ctx).
 		Where("code = ? AND (expires_at IS NULL OR expires_at > ?)", code, time.Now()).
-		Where("max_uses IS NULL OR current_uses < max_uses").
+		Where("max_uses IS NULL OR max_uses = 0 OR current_uses < max_uses"). // 0 = tanpa batas
 		First(&coupon).Error
 
 	if err != nil {
-		return nil, err // Kembalikan gorm.ErrRecordNotFound jika tidak ada
+		return nil, notFound(err, ErrCouponNotFound)
+	}
+
+	inScope, err := isCouponInScope(r.db.WithContext(ctx), coupon.ID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !inScope {
+		return nil, ErrCouponNotApplicable
 	}
 	return &coupon, nil
 }
 
+// RedeemCoupon memakai satu kuota kupon secara atomik.
+// Baris kupon dikunci (SELECT ... FOR UPDATE) sehingga dua checkout
+// yang bersamaan tidak bisa melewati MaxUses.
+func (r *courseRepository) RedeemCoupon(ctx context.Context, code string, courseID uuid.UUID, authID string) (*models.Coupon, *models.CouponRedemption, error) {
+	var coupon models.Coupon
+	var redemption *models.CouponRedemption
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci baris kupon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&coupon).Error; err != nil {
+			return notFound(err, ErrCouponNotFound)
+		}
+
+		// 2. Validasi ulang di dalam transaksi
+		if coupon.ExpiresAt != nil && !coupon.ExpiresAt.After(time.Now()) {
+			return ErrCouponNotFound // Kupon kedaluwarsa diperlakukan seperti tidak ada
+		}
+		if coupon.MaxUses > 0 && coupon.CurrentUses >= coupon.MaxUses {
+			return ErrCouponExhausted
+		}
+		inScope, err := isCouponInScope(tx, coupon.ID, courseID)
+		if err != nil {
+			return err
+		}
+		if !inScope {
+			return ErrCouponNotApplicable
+		}
+
+		// 3. Tambah pemakaian & catat redemption
+		if err := tx.Model(&coupon).Update("current_uses", gorm.Expr("current_uses + 1")).Error; err != nil {
+			return err
+		}
+		coupon.CurrentUses++
+
+		redemption = &models.CouponRedemption{
+			CouponID:   coupon.ID,
+			CourseID:   courseID,
+			AuthID:     authID,
+			RedeemedAt: time.Now(),
+		}
+		return tx.Create(redemption).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &coupon, redemption, nil
+}
+
+// ReleaseCoupon mengembalikan kuota dari sebuah redemption (misal pembayaran gagal).
+// Redemption yang sudah di-release tidak mengurangi kuota dua kali.
+func (r *courseRepository) ReleaseCoupon(ctx context.Context, redemptionID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci redemption
+		var redemption models.CouponRedemption
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", redemptionID).First(&redemption).Error; err != nil {
+			return notFound(err, ErrRedemptionNotFound)
+		}
+		if redemption.ReleasedAt != nil {
+			return ErrRedemptionReleased
+		}
+
+		// 2. Tandai sudah di-release
+		if err := tx.Model(&redemption).Update("released_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		// 3. Kembalikan kuota kupon
+		return tx.Model(&models.Coupon{}).
+			Where("id = ? AND current_uses > 0", redemption.CouponID).
+			Update("current_uses", gorm.Expr("current_uses - 1")).Error
+	})
+}
+
+// isCouponInScope mengecek apakah kupon berlaku untuk course:
+// terhubung langsung via 'coupon_courses', atau via 'coupon_categories'
+// ke salah satu kategori course (termasuk semua leluhurnya).
+// Kupon tanpa relasi sama sekali dianggap global.
+func isCouponInScope(db *gorm.DB, couponID, courseID uuid.UUID) (bool, error) {
+	var inScope bool
+	err := db.Raw(`
+		WITH RECURSIVE course_cats AS (
+			SELECT c.id, c.parent_id FROM categories c
+			JOIN course_categories cc ON cc.category_id = c.id
+			WHERE cc.course_id = @course
+			UNION
+			SELECT p.id, p.parent_id FROM categories p
+			JOIN course_cats ch ON ch.parent_id = p.id
+		)
+		SELECT
+			EXISTS (SELECT 1 FROM coupon_courses WHERE coupon_id = @coupon AND course_id = @course)
+			OR EXISTS (SELECT 1 FROM coupon_categories WHERE coupon_id = @coupon AND category_id IN (SELECT id FROM course_cats))
+			OR (
+				NOT EXISTS (SELECT 1 FROM coupon_courses WHERE coupon_id = @coupon)
+				AND NOT EXISTS (SELECT 1 FROM coupon_categories WHERE coupon_id = @coupon)
+			)`,
+		map[string]interface{}{"coupon": couponID, "course": courseID},
+	).Scan(&inScope).Error
+	return inScope, err
+}
+
 func (r *courseRepository) GetActiveSalesForCourse(ctx context.Context, courseID uuid.UUID) ([]*models.Sale, error) {
 	var sales []*models.Sale
 	now := time.Now()
@@ -378,29 +706,54 @@ func (r *courseRepository) UpdateCourseTags(ctx context.Context, courseID uuid.U
 	return r.db.WithContext(ctx).Model(&course).Association("Tags").Replace(tags)
 }
 
-// ✅
-func (r *courseRepository) CreateChapter(ctx context.Context, chapter *models.Chapter) error {
-	// ('chapter.ID' dan 'chapter.Slug' harus sudah di-set oleh handler)
-	return r.db.WithContext(ctx).Create(chapter).Error
-}
-
 // ✅
 // GetChapterByID mengambil satu bab
 func (r *courseRepository) GetChapterByID(ctx context.Context, chapterID uuid.UUID) (*models.Chapter, error) {
 	var chapter models.Chapter
 	err := r.db.WithContext(ctx).Where("id = ?", chapterID).First(&chapter).Error
 	if err != nil {
-		return nil, err // Akan GORM.ErrRecordNotFound jika tidak ada
+		return nil, notFound(err, ErrChapterNotFound)
 	}
 	return &chapter, nil
 }
 
 // ✅
 // UpdateChapter memperbarui data bab
+// 'chapter.Version' adalah versi yang dilihat client; update ditolak dengan
+// ErrVersionConflict jika baris sudah diubah orang lain (0 = tanpa cek).
 func (r *courseRepository) UpdateChapter(ctx context.Context, chapter *models.Chapter) (*models.Chapter, error) {
-	// Gunakan .Save() untuk memperbarui semua field,
-	// atau .Model() & .Updates() untuk field spesifik
-	err := r.db.WithContext(ctx).Save(chapter).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci course (judul/urutan chapter bagian dari versi kurikulum)
+		courseID, err := courseIDOfChapter(tx, chapter.ID)
+		if err != nil {
+			return notFound(err, ErrChapterNotFound)
+		}
+		if err := touchCurriculum(tx, courseID); err != nil {
+			return err
+		}
+
+		// Compare-and-swap pada kolom 'version'
+		query := tx.Model(&models.Chapter{}).Where("id = ?", chapter.ID)
+		if chapter.Version != 0 {
+			query = query.Where("version = ?", chapter.Version)
+		}
+		result := query.Updates(map[string]interface{}{
+			"title":   chapter.Title,
+			"order":   chapter.Order,
+			"version": gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		if err := tx.First(chapter, "id = ?", chapter.ID).Error; err != nil {
+			return err
+		}
+		return enqueueCurriculumChanged(tx, chapter.CourseID, "chapter.updated", chapter.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -408,36 +761,56 @@ func (r *courseRepository) UpdateChapter(ctx context.Context, chapter *models.Ch
 }
 
 // ✅ 
-// ReorderChapters menjalankan batch update dalam satu transaksi
-func (r *courseRepository) ReorderChapters(ctx context.Context, courseID uuid.UUID, updates []ChapterReorderInput) error {
+// ReorderChapters menyimpan urutan baru chapter milik course dalam satu
+// transaksi. Payload harus memuat semua chapter course tepat satu kali.
+// 'version' adalah versi course yang dilihat editor (If-Match); mengembalikan
+// versi course yang baru.
+func (r *courseRepository) ReorderChapters(ctx context.Context, courseID uuid.UUID, version int64, updates []ChapterReorderInput) (int64, error) {
 	// Memulai transaksi
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		
-		for _, item := range updates {
-			// Menjalankan update di dalam transaksi
-			// Kita juga memverifikasi 'course_id' untuk keamanan ekstra,
-			// memastikan chapter yang di-update milik kursus yang benar.
-			result := tx.Model(&models.Chapter{}).
-				Where("id = ? AND course_id = ?", item.ID, courseID).
-				Update("order", item.Order)
-
-			if result.Error != nil {
-				// Jika satu update gagal, seluruh transaksi di-rollback
-				return result.Error
-			}
-			
-			if result.RowsAffected == 0 {
-				// Ini terjadi jika chapterId tidak ada ATAU tidak cocok dengan courseId
-				// Kita batalkan transaksi untuk mencegah update parsial.
-				return errors.New(
-					fmt.Sprintf("Reorder failed: Chapter ID %s not found or does not belong to course ID %s", item.ID, courseID),
-				)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci course & cek versi dulu (sama seperti perubahan kurikulum
+		//    lain, agar tidak berpapasan dengan cek readiness di UpdateCourseStatus)
+		current, err := lockCurriculum(tx, courseID, version)
+		if err != nil {
+			return err
+		}
+
+		// 2. Cocokkan payload dengan chapter milik course ini
+		var stored []uuid.UUID
+		if err := tx.Model(&models.Chapter{}).Where("course_id = ?", courseID).Pluck("id", &stored).Error; err != nil {
+			return err
+		}
+		items := make([]reorderItem, len(updates))
+		for i, item := range updates {
+			items[i] = reorderItem{ID: item.ID, Order: item.Order}
+		}
+		ordered, err := planReorder(items, stored, func(id uuid.UUID) error {
+			return ErrChapterNotInCourse.With("chapterId", id)
+		})
+		if err != nil {
+			return err
+		}
+
+		// 3. Simpan urutan 1..n (satu gagal = seluruh transaksi di-rollback)
+		for i, id := range ordered {
+			err := tx.Model(&models.Chapter{}).
+				Where("id = ?", id).
+				Updates(map[string]interface{}{"order": i + 1, "version": gorm.Expr("version + 1")}).Error
+			if err != nil {
+				return err
 			}
 		}
+		if version, err = bumpCourseVersion(tx, courseID, current); err != nil {
+			return err
+		}
 
-		// Jika semua loop berhasil, commit transaksi
-		return nil
+		// Jika semua loop berhasil, catat event lalu commit transaksi
+		return enqueueCurriculumChanged(tx, courseID, "chapters.reordered", courseID)
 	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
 
@@ -449,12 +822,12 @@ func (r *courseRepository) DeleteChapter(ctx context.Context, courseID uuid.UUID
 		
 		// 1. Pastikan chapter ini milik course yang benar
 		//    (Ini juga berfungsi sebagai cek kepemilikan)
+		if err := touchCurriculum(tx, courseID); err != nil {
+			return err
+		}
 		var chapter models.Chapter
 		if err := tx.Where("id = ? AND course_id = ?", chapterID, courseID).First(&chapter).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return errors.New("chapter not found or does not belong to this course")
-			}
-			return err // Error DB lain
+			return notFound(err, ErrChapterNotFound)
 		}
 
 		// 2. Hapus semua lesson di dalam chapter ini
@@ -468,19 +841,12 @@ func (r *courseRepository) DeleteChapter(ctx context.Context, courseID uuid.UUID
 			return err // Rollback jika gagal hapus chapter
 		}
 
-		// 4. Commit transaksi
-		return nil
+		// 4. Catat event, lalu commit transaksi
+		return enqueueCurriculumChanged(tx, courseID, "chapter.deleted", chapterID)
 	})
 }
 
 
-// ✅
-// CreateLesson membuat lesson baru
-func (r *courseRepository) CreateLesson(ctx context.Context, lesson *models.Lesson) error {
-	return r.db.WithContext(ctx).Create(lesson).Error
-}
-
-
 // ✅
 // GetLessonByID mengambil satu lesson berdasarkan ID
 func (r *courseRepository) GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error) {
@@ -493,18 +859,377 @@ func (r *courseRepository) GetLessonByID(ctx context.Context, lessonID uuid.UUID
 		First(&lesson).Error
 		
 	if err != nil {
-		return nil, err // Akan GORM.ErrRecordNotFound jika tidak ada
+		return nil, notFound(err, ErrLessonNotFound)
 	}
 	return &lesson, nil
 }
 
 // ✅
 // UpdateLesson memperbarui data lesson
+// 'lesson.Version' adalah versi yang dilihat client (lihat UpdateChapter)
 func (r *courseRepository) UpdateLesson(ctx context.Context, lesson *models.Lesson) (*models.Lesson, error) {
-	// Gunakan .Save() untuk memperbarui semua field
-	err := r.db.WithContext(ctx).Save(lesson).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci course (playbackId kosong memengaruhi readiness)
+		var current models.Lesson
+		err := tx.Joins("JOIN chapters ON chapters.id = lessons.chapter_id").
+			Select("lessons.id, chapters.course_id").
+			Where("lessons.id = ?", lesson.ID).
+			First(&current).Error
+		if err != nil {
+			return notFound(err, ErrLessonNotFound)
+		}
+		if err := touchCurriculum(tx, current.CourseID); err != nil {
+			return err
+		}
+
+		// Compare-and-swap pada kolom 'version'
+		query := tx.Model(&models.Lesson{}).Where("id = ?", lesson.ID)
+		if lesson.Version != 0 {
+			query = query.Where("version = ?", lesson.Version)
+		}
+		result := query.Updates(map[string]interface{}{
+			"title":       lesson.Title,
+			"order":       lesson.Order,
+			"playback_id": lesson.PlaybackID,
+			"is_preview":  lesson.IsPreview,
+			"version":     gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		err = tx.Joins("JOIN chapters ON chapters.id = lessons.chapter_id").
+			Select("lessons.*, chapters.course_id").
+			Where("lessons.id = ?", lesson.ID).
+			First(lesson).Error
+		if err != nil {
+			return err
+		}
+		return enqueueCurriculumChanged(tx, lesson.CourseID, "lesson.updated", lesson.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return lesson, nil
 }
+
+// DeleteLesson menghapus lesson lalu merapikan urutan lesson
+// yang tersisa di chapter-nya (transaksional)
+func (r *courseRepository) DeleteLesson(ctx context.Context, lessonID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Ambil lesson (sekaligus courseId untuk event)
+		var lesson models.Lesson
+		err := tx.Joins("JOIN chapters ON chapters.id = lessons.chapter_id").
+			Select("lessons.*, chapters.course_id").
+			Where("lessons.id = ?", lessonID).
+			First(&lesson).Error
+		if err != nil {
+			return notFound(err, ErrLessonNotFound)
+		}
+
+		// 2. Kunci course & chapter agar renumber tidak bentrok dengan operasi lain
+		if err := touchCurriculum(tx, lesson.CourseID); err != nil {
+			return err
+		}
+		if err := lockChapters(tx, lesson.ChapterID); err != nil {
+			return err
+		}
+
+		// 3. Hapus lesson (progress ikut terhapus via ON DELETE CASCADE)
+		if err := tx.Where("id = ?", lessonID).Delete(&models.Lesson{}).Error; err != nil {
+			return err
+		}
+
+		// 4. Rapikan urutan lesson yang tersisa
+		if err := renumberLessons(tx, lesson.ChapterID, nil, 0); err != nil {
+			return err
+		}
+
+		return enqueueCurriculumChanged(tx, lesson.CourseID, "lesson.deleted", lessonID)
+	})
+}
+
+// ReorderLessons menyimpan urutan baru lesson dalam satu chapter
+// (sama seperti ReorderChapters: payload memuat semua lesson chapter, dan
+// 'version' adalah versi course, bukan versi chapter)
+func (r *courseRepository) ReorderLessons(ctx context.Context, chapterID uuid.UUID, version int64, updates []LessonReorderInput) (int64, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Course pemilik chapter (sekali, sebelum loop), lalu kunci
+		//    course (cek versi) & chapter seperti MoveLesson/DeleteLesson
+		courseID, err := courseIDOfChapter(tx, chapterID)
+		if err != nil {
+			return notFound(err, ErrChapterNotFound)
+		}
+		current, err := lockCurriculum(tx, courseID, version)
+		if err != nil {
+			return err
+		}
+		if err := lockChapters(tx, chapterID); err != nil {
+			return notFound(err, ErrChapterNotFound)
+		}
+
+		// 2. Cocokkan payload dengan lesson milik chapter ini
+		var stored []uuid.UUID
+		if err := tx.Model(&models.Lesson{}).Where("chapter_id = ?", chapterID).Pluck("id", &stored).Error; err != nil {
+			return err
+		}
+		items := make([]reorderItem, len(updates))
+		for i, item := range updates {
+			items[i] = reorderItem{ID: item.ID, Order: item.Order}
+		}
+		ordered, err := planReorder(items, stored, func(id uuid.UUID) error {
+			return ErrLessonNotInChapter.With("lessonId", id)
+		})
+		if err != nil {
+			return err
+		}
+
+		// 3. Simpan urutan 1..n
+		for i, id := range ordered {
+			err := tx.Model(&models.Lesson{}).
+				Where("id = ?", id).
+				Updates(map[string]interface{}{"order": i + 1, "version": gorm.Expr("version + 1")}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if version, err = bumpCourseVersion(tx, courseID, current); err != nil {
+			return err
+		}
+
+		return enqueueCurriculumChanged(tx, courseID, "lessons.reordered", chapterID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// reorderItem adalah satu item payload reorder (chapter atau lesson)
+type reorderItem struct {
+	ID    uuid.UUID
+	Order int
+}
+
+// planReorder mencocokkan payload reorder dengan anggota yang tersimpan
+// (chapter milik course / lesson milik chapter) dan mengembalikan ID dalam
+// urutan baru. Setiap anggota harus muncul tepat satu kali: ID asing ->
+// notMember(id), ID ganda atau anggota yang terlewat -> ErrReorderMismatch
+// (urutan lama anggota yang terlewat akan bentrok dengan urutan baru).
+// 'Order' hanya menentukan urutan relatif; hasilnya selalu 1..n.
+func planReorder(items []reorderItem, stored []uuid.UUID, notMember func(id uuid.UUID) error) ([]uuid.UUID, error) {
+	members := make(map[uuid.UUID]bool, len(stored))
+	for _, id := range stored {
+		members[id] = true
+	}
+
+	seen := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		if !members[item.ID] {
+			return nil, notMember(item.ID)
+		}
+		if seen[item.ID] {
+			return nil, ErrReorderMismatch.With("duplicate", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	missing := []uuid.UUID{}
+	for _, id := range stored {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, ErrReorderMismatch.With("missing", missing)
+	}
+
+	// Urut berdasarkan 'Order'; seri -> posisi di payload
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b reorderItem) int {
+		return cmp.Compare(a.Order, b.Order)
+	})
+	ordered := make([]uuid.UUID, len(sorted))
+	for i, item := range sorted {
+		ordered[i] = item.ID
+	}
+	return ordered, nil
+}
+
+// MoveLesson memindahkan lesson ke chapter lain (di course yang sama) pada
+// posisi 'position' (mulai dari 1; 0 atau melebihi jumlah lesson = paling akhir).
+// Urutan lesson di chapter asal dan tujuan dinomori ulang secara atomik.
+// 'version' adalah versi course yang dilihat editor (If-Match); mengembalikan
+// lesson hasil pindah beserta versi course yang baru.
+func (r *courseRepository) MoveLesson(ctx context.Context, lessonID, targetChapterID uuid.UUID, position int, version int64) (*models.Lesson, int64, error) {
+	var lesson models.Lesson
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Ambil lesson
+		err := tx.Joins("JOIN chapters ON chapters.id = lessons.chapter_id").
+			Select("lessons.*, chapters.course_id").
+			Where("lessons.id = ?", lessonID).
+			First(&lesson).Error
+		if err != nil {
+			return notFound(err, ErrLessonNotFound)
+		}
+		sourceChapterID := lesson.ChapterID
+
+		// 2. Kunci course (cek versi) & kedua chapter, lalu pastikan satu course
+		current, err := lockCurriculum(tx, lesson.CourseID, version)
+		if err != nil {
+			return err
+		}
+		if err := lockChapters(tx, sourceChapterID, targetChapterID); err != nil {
+			return notFound(err, ErrChapterNotFound)
+		}
+		targetCourseID, err := courseIDOfChapter(tx, targetChapterID)
+		if err != nil {
+			return notFound(err, ErrChapterNotFound)
+		}
+		if targetCourseID != lesson.CourseID {
+			return ErrLessonMoveCourse
+		}
+
+		// 3. Pindahkan, lalu nomori ulang kedua chapter
+		err = tx.Model(&models.Lesson{}).
+			Where("id = ?", lessonID).
+			Updates(map[string]interface{}{"chapter_id": targetChapterID, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+		if sourceChapterID != targetChapterID {
+			if err := renumberLessons(tx, sourceChapterID, nil, 0); err != nil {
+				return err
+			}
+		}
+		if err := renumberLessons(tx, targetChapterID, &lessonID, position); err != nil {
+			return err
+		}
+
+		// 4. Baca ulang hasil akhirnya
+		err = tx.Joins("JOIN chapters ON chapters.id = lessons.chapter_id").
+			Select("lessons.*, chapters.course_id").
+			Where("lessons.id = ?", lessonID).
+			First(&lesson).Error
+		if err != nil {
+			return err
+		}
+		if version, err = bumpCourseVersion(tx, lesson.CourseID, current); err != nil {
+			return err
+		}
+
+		return enqueueCurriculumChanged(tx, lesson.CourseID, "lesson.moved", lessonID)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &lesson, version, nil
+}
+
+// lockCurriculum mengunci baris course (FOR NO KEY UPDATE) selama
+// kurikulumnya diubah: perubahan kurikulum untuk course yang sama jadi
+// berurutan, dan UpdateCourseStatus (FOR UPDATE) ikut menunggu sehingga cek
+// readiness tidak bisa berpapasan dengan perubahan kurikulum.
+// 'expected' adalah versi course yang dilihat editor (If-Match); versi lain
+// -> ErrVersionConflict (0 = tanpa cek). Mengembalikan versi saat ini.
+func lockCurriculum(tx *gorm.DB, courseID uuid.UUID, expected int64) (int64, error) {
+	var course models.Course
+	err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+		Select("id", "version").
+		Where("id = ?", courseID).
+		First(&course).Error
+	if err != nil {
+		return 0, notFound(err, ErrCourseNotFound)
+	}
+	if expected != 0 && expected != course.Version {
+		return course.Version, ErrVersionConflict
+	}
+	return course.Version, nil
+}
+
+// bumpCourseVersion menaikkan course.version setelah tree kurikulum berubah,
+// agar editor lain yang masih memegang versi lama ditolak (412).
+// 'current' adalah versi dari lockCurriculum; mengembalikan versi baru.
+func bumpCourseVersion(tx *gorm.DB, courseID uuid.UUID, current int64) (int64, error) {
+	next := current + 1
+	err := tx.Model(&models.Course{}).Where("id = ?", courseID).Update("version", next).Error
+	return next, err
+}
+
+// touchCurriculum mengunci course lalu menaikkan versinya, untuk perubahan
+// satu node (create/update/delete chapter atau lesson) yang tidak membawa
+// If-Match course
+func touchCurriculum(tx *gorm.DB, courseID uuid.UUID) error {
+	version, err := lockCurriculum(tx, courseID, 0)
+	if err != nil {
+		return err
+	}
+	_, err = bumpCourseVersion(tx, courseID, version)
+	return err
+}
+
+// lockChapters mengunci baris chapter (urut id, agar tidak deadlock).
+// gorm.ErrRecordNotFound jika salah satu chapter tidak ada.
+func lockChapters(tx *gorm.DB, chapterIDs ...uuid.UUID) error {
+	ids := uniqueIDs(chapterIDs)
+	var chapters []models.Chapter
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&chapters).Error
+	if err != nil {
+		return err
+	}
+	if len(chapters) != len(ids) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// renumberLessons memberi nomor urut 1..n ke lesson di chapter.
+// Jika 'placed' diisi, lesson itu diletakkan di 'position' (1-based;
+// 0 atau di luar jangkauan = paling akhir), sisanya mengikuti urutan lama.
+func renumberLessons(tx *gorm.DB, chapterID uuid.UUID, placed *uuid.UUID, position int) error {
+	var lessons []models.Lesson
+	err := tx.Select("id", "order").
+		Where("chapter_id = ?", chapterID).
+		Order("lessons.order ASC, lessons.id ASC").
+		Find(&lessons).Error
+	if err != nil {
+		return err
+	}
+
+	ordered := make([]uuid.UUID, 0, len(lessons))
+	for _, lesson := range lessons {
+		if placed == nil || lesson.ID != *placed {
+			ordered = append(ordered, lesson.ID)
+		}
+	}
+	if placed != nil {
+		if position < 1 || position > len(ordered) {
+			position = len(ordered) + 1
+		}
+		ordered = append(ordered[:position-1], append([]uuid.UUID{*placed}, ordered[position-1:]...)...)
+	}
+
+	current := make(map[uuid.UUID]int, len(lessons))
+	for _, lesson := range lessons {
+		current[lesson.ID] = lesson.Order
+	}
+	for i, id := range ordered {
+		if current[id] == i+1 {
+			continue
+		}
+		err := tx.Model(&models.Lesson{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{"order": i + 1, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
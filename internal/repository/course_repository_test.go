@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestPlanReorder(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	foreign := uuid.New()
+	stored := []uuid.UUID{a, b, c}
+	notMember := func(id uuid.UUID) error { return ErrChapterNotInCourse.With("chapterId", id) }
+
+	tests := []struct {
+		name        string
+		items       []reorderItem
+		wantOrdered []uuid.UUID
+		wantErr     error
+	}{
+		{name: "full permutation", items: []reorderItem{{a, 3}, {b, 1}, {c, 2}}, wantOrdered: []uuid.UUID{b, c, a}},
+		{name: "gaps are normalised", items: []reorderItem{{a, 10}, {b, 30}, {c, 20}}, wantOrdered: []uuid.UUID{a, c, b}},
+		{name: "ties keep payload position", items: []reorderItem{{c, 1}, {a, 1}, {b, 2}}, wantOrdered: []uuid.UUID{c, a, b}},
+		{name: "missing member", items: []reorderItem{{a, 1}, {b, 2}}, wantErr: ErrReorderMismatch},
+		{name: "duplicate member", items: []reorderItem{{a, 1}, {b, 2}, {a, 3}}, wantErr: ErrReorderMismatch},
+		{name: "foreign id", items: []reorderItem{{a, 1}, {b, 2}, {c, 3}, {foreign, 4}}, wantErr: ErrChapterNotInCourse},
+		{name: "empty payload for non-empty parent", items: nil, wantErr: ErrReorderMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := planReorder(tt.items, stored, notMember)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ordered, tt.wantOrdered) {
+				t.Fatalf("ordered = %v, want %v", ordered, tt.wantOrdered)
+			}
+		})
+	}
+
+	// Parent tanpa anggota: payload kosong sah
+	if ordered, err := planReorder(nil, nil, notMember); err != nil || len(ordered) != 0 {
+		t.Fatalf("empty parent = %v, %v; want empty, nil", ordered, err)
+	}
+}
+
+func TestReorderChaptersChecksCourseVersion(t *testing.T) {
+	courseID := uuid.New()
+
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows // Hasil SELECT ... FOR NO KEY UPDATE
+		wantErr error
+	}{
+		{name: "stale version", rows: sqlmock.NewRows([]string{"id", "version"}).AddRow(courseID, 5), wantErr: ErrVersionConflict},
+		{name: "unknown course", rows: sqlmock.NewRows([]string{"id", "version"}), wantErr: ErrCourseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT "id","version" FROM "courses" .* FOR NO KEY UPDATE`).WillReturnRows(tt.rows)
+			mock.ExpectRollback() // Tidak ada UPDATE setelah versi ditolak
+
+			_, err := NewCourseRepository(db).ReorderChapters(context.Background(), courseID, 4, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
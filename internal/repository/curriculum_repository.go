@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// MaxCurriculumTitleLength adalah panjang maksimum judul chapter/lesson
+const MaxCurriculumTitleLength = 200
+
+// CurriculumLessonInput adalah satu lesson di tree kurikulum.
+// ID kosong = lesson baru; ClientID dipakai editor untuk mencocokkan
+// node baru dengan ID yang diberikan server.
+type CurriculumLessonInput struct {
+	ID         *uuid.UUID `json:"id"`
+	ClientID   string     `json:"clientId"`
+	Title      string     `json:"title"`
+	PlaybackID string     `json:"playbackId"`
+	IsPreview  bool       `json:"isPreview"`
+}
+
+// CurriculumChapterInput adalah satu chapter di tree kurikulum.
+// Urutan chapter & lesson diambil dari posisi di array.
+type CurriculumChapterInput struct {
+	ID       *uuid.UUID              `json:"id"`
+	ClientID string                  `json:"clientId"`
+	Title    string                  `json:"title"`
+	Slug     string                  `json:"-"` // Di-set oleh service untuk chapter baru
+	Lessons  []CurriculumLessonInput `json:"lessons"`
+}
+
+// CurriculumSaveResult adalah tree hasil normalisasi setelah disimpan
+type CurriculumSaveResult struct {
+	Version     int64                `json:"version"` // Versi course (ETag untuk autosave berikutnya)
+	Chapters    []models.Chapter     `json:"chapters"`
+	AssignedIDs map[string]uuid.UUID `json:"assignedIds"` // clientId -> id untuk node baru
+}
+
+// SaveCurriculum membandingkan tree dari editor dengan yang tersimpan, lalu
+// menjalankan create, update, delete dan reorder dalam satu transaksi.
+// Chapter/lesson yang tidak ada di tree akan dihapus. Lesson boleh pindah
+// chapter (selama masih di course yang sama).
+//
+// 'version' adalah versi course yang dilihat editor (If-Match). Tab editor
+// yang usang ditolak dengan ErrVersionConflict sebelum apa pun diubah; jika
+// tree berubah, versi course naik (Version di hasil).
+func (r *courseRepository) SaveCurriculum(ctx context.Context, courseID uuid.UUID, version int64, chapters []CurriculumChapterInput) (*CurriculumSaveResult, error) {
+	result := &CurriculumSaveResult{AssignedIDs: map[string]uuid.UUID{}}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci course & cek versi (autosave untuk course yang sama jadi berurutan)
+		current, err := lockCurriculum(tx, courseID, version)
+		if err != nil {
+			return err
+		}
+		result.Version = current
+		changed := false
+
+		// 2. Muat kurikulum yang tersimpan
+		var stored []models.Chapter
+		if err := tx.Preload("Lessons").Where("course_id = ?", courseID).Find(&stored).Error; err != nil {
+			return err
+		}
+		storedChapters := make(map[uuid.UUID]*models.Chapter, len(stored))
+		storedLessons := make(map[uuid.UUID]*models.Lesson)
+		for i := range stored {
+			storedChapters[stored[i].ID] = &stored[i]
+			for j := range stored[i].Lessons {
+				storedLessons[stored[i].Lessons[j].ID] = &stored[i].Lessons[j]
+			}
+		}
+
+		// 3. Validasi seluruh tree dulu (kumpulkan semua error)
+		if errs := validateCurriculum(chapters, storedChapters, storedLessons); len(errs) > 0 {
+			return ErrInvalidCurriculum.WithFields(errs...)
+		}
+
+		// 4. Chapter: buat yang baru, update judul/urutan yang berubah
+		keepChapters := make(map[uuid.UUID]bool)
+		keepLessons := make(map[uuid.UUID]bool)
+		for i := range chapters {
+			input := &chapters[i]
+			order := i + 1
+			title := strings.TrimSpace(input.Title)
+
+			if input.ID == nil {
+				chapter := models.Chapter{CourseID: courseID, Title: title, Order: order, Slug: input.Slug}
+				if err := tx.Create(&chapter).Error; err != nil {
+					return err
+				}
+				changed = true
+				input.ID = &chapter.ID
+				if input.ClientID != "" {
+					result.AssignedIDs[input.ClientID] = chapter.ID
+				}
+			} else if existing := storedChapters[*input.ID]; existing.Title != title || existing.Order != order {
+				err := tx.Model(&models.Chapter{}).
+					Where("id = ?", existing.ID).
+					Updates(map[string]interface{}{"title": title, "order": order, "version": gorm.Expr("version + 1")}).Error
+				if err != nil {
+					return err
+				}
+				changed = true
+			}
+			keepChapters[*input.ID] = true
+
+			// 5. Lesson: buat yang baru, update yang berubah (termasuk pindah chapter)
+			for j := range input.Lessons {
+				lessonInput := &input.Lessons[j]
+				lessonOrder := j + 1
+				lessonTitle := strings.TrimSpace(lessonInput.Title)
+
+				if lessonInput.ID == nil {
+					lesson := models.Lesson{
+						ChapterID:  *input.ID,
+						Title:      lessonTitle,
+						Order:      lessonOrder,
+						PlaybackID: lessonInput.PlaybackID,
+						IsPreview:  lessonInput.IsPreview,
+					}
+					if err := tx.Create(&lesson).Error; err != nil {
+						return err
+					}
+					changed = true
+					lessonInput.ID = &lesson.ID
+					if lessonInput.ClientID != "" {
+						result.AssignedIDs[lessonInput.ClientID] = lesson.ID
+					}
+				} else {
+					existing := storedLessons[*lessonInput.ID]
+					if existing.ChapterID != *input.ID || existing.Title != lessonTitle || existing.Order != lessonOrder ||
+						existing.PlaybackID != lessonInput.PlaybackID || existing.IsPreview != lessonInput.IsPreview {
+						err := tx.Model(&models.Lesson{}).
+							Where("id = ?", existing.ID).
+							Updates(map[string]interface{}{
+								"chapter_id":  *input.ID,
+								"title":       lessonTitle,
+								"order":       lessonOrder,
+								"playback_id": lessonInput.PlaybackID,
+								"is_preview":  lessonInput.IsPreview,
+								"version":     gorm.Expr("version + 1"),
+							}).Error
+						if err != nil {
+							return err
+						}
+						changed = true
+					}
+				}
+				keepLessons[*lessonInput.ID] = true
+			}
+		}
+
+		// 6. Hapus lesson lalu chapter yang tidak ada lagi di tree
+		//    (lesson yang pindah chapter sudah di-update di langkah 5)
+		var deleteLessons, deleteChapters []uuid.UUID
+		for id := range storedLessons {
+			if !keepLessons[id] {
+				deleteLessons = append(deleteLessons, id)
+			}
+		}
+		for id := range storedChapters {
+			if !keepChapters[id] {
+				deleteChapters = append(deleteChapters, id)
+			}
+		}
+		if len(deleteLessons) > 0 {
+			if err := tx.Where("id IN ?", deleteLessons).Delete(&models.Lesson{}).Error; err != nil {
+				return err
+			}
+			changed = true
+		}
+		if len(deleteChapters) > 0 {
+			if err := tx.Where("id IN ?", deleteChapters).Delete(&models.Chapter{}).Error; err != nil {
+				return err
+			}
+			changed = true
+		}
+
+		// 7. Tree berubah -> naikkan versi course (autosave tanpa perubahan
+		//    tidak membuat tab lain usang)
+		if changed {
+			if result.Version, err = bumpCourseVersion(tx, courseID, current); err != nil {
+				return err
+			}
+		}
+
+		// 8. Muat ulang tree yang sudah dinormalisasi
+		err = tx.Preload("Lessons", func(db *gorm.DB) *gorm.DB {
+			return db.Order("lessons.order ASC")
+		}).
+			Where("course_id = ?", courseID).
+			Order("chapters.order ASC").
+			Find(&result.Chapters).Error
+		if err != nil || !changed {
+			return err
+		}
+
+		return enqueueCurriculumChanged(tx, courseID, "curriculum.saved", courseID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateCurriculum mengecek tree terhadap data yang tersimpan dan
+// mengembalikan semua error per node (kosong = valid). Field berisi path
+// node, misal: chapters[1].lessons[0].title
+func validateCurriculum(chapters []CurriculumChapterInput, storedChapters map[uuid.UUID]*models.Chapter, storedLessons map[uuid.UUID]*models.Lesson) []apperr.FieldError {
+	errs := []apperr.FieldError{}
+	seen := make(map[uuid.UUID]bool)
+	seenClientIDs := make(map[string]bool)
+
+	addError := func(path, code, param string) {
+		errs = append(errs, apperr.FieldError{Field: path, Code: code, Param: param})
+	}
+	checkNode := func(path string, id *uuid.UUID, clientID, title string, known bool) {
+		if id != nil {
+			if seen[*id] {
+				addError(path+".id", "DUPLICATE", "")
+			} else if !known {
+				addError(path+".id", "NOT_FOUND", "")
+			}
+			seen[*id] = true
+		} else if clientID != "" {
+			if seenClientIDs[clientID] {
+				addError(path+".clientId", "DUPLICATE", "")
+			}
+			seenClientIDs[clientID] = true
+		}
+
+		title = strings.TrimSpace(title)
+		switch {
+		case title == "":
+			addError(path+".title", "REQUIRED", "")
+		case len([]rune(title)) > MaxCurriculumTitleLength:
+			addError(path+".title", "MAX", strconv.Itoa(MaxCurriculumTitleLength))
+		}
+	}
+
+	for i, chapter := range chapters {
+		path := fmt.Sprintf("chapters[%d]", i)
+		known := chapter.ID != nil && storedChapters[*chapter.ID] != nil
+		checkNode(path, chapter.ID, chapter.ClientID, chapter.Title, known)
+
+		for j, lesson := range chapter.Lessons {
+			lessonPath := fmt.Sprintf("%s.lessons[%d]", path, j)
+			known := lesson.ID != nil && storedLessons[*lesson.ID] != nil
+			checkNode(lessonPath, lesson.ID, lesson.ClientID, lesson.Title, known)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// GrantEnrollmentInput adalah data untuk memberi akses student ke course
+type GrantEnrollmentInput struct {
+	AuthID    string
+	CourseID  uuid.UUID
+	Source    models.EnrollmentSource
+	Reference string     // Misal ID order (opsional)
+	ExpiresAt *time.Time // nil = selamanya
+}
+
+// findOrCreateStudent mencari student by AuthID, atau membuat "profil bayangan"
+// (sama seperti teacher). ON CONFLICT menangani dua request bersamaan.
+func findOrCreateStudent(tx *gorm.DB, authID string) (*models.Student, error) {
+	var student models.Student
+	err := tx.Where("auth_id = ?", authID).First(&student).Error
+	if err == nil {
+		return &student, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	student = models.Student{
+		AuthID:   authID,
+		Name:     "Pending Sync",                // Placeholder
+		Username: "pending-" + uuid.NewString(), // Placeholder unik
+	}
+	err = tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "auth_id"}}, DoNothing: true}).
+		Create(&student).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Baca ulang: jika kalah balapan, ambil baris milik request lain
+	if err := tx.Where("auth_id = ?", authID).First(&student).Error; err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+// GrantEnrollment memberi akses student ke course.
+// Jika sudah ada enrollment aktif, enrollment itu diperbarui (bukan duplikat):
+// masa berlaku diperpanjang, source/reference diganti dengan grant terbaru.
+// Aman dipanggil ulang oleh Payment-service. Mengembalikan 'created' = true
+// jika enrollment baru dibuat.
+func (r *courseRepository) GrantEnrollment(ctx context.Context, input GrantEnrollmentInput) (*models.Enrollment, bool, error) {
+	var enrollment models.Enrollment
+	created := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Pastikan course ada
+		var course models.Course
+		if err := tx.Select("id").Where("id = ?", input.CourseID).First(&course).Error; err != nil {
+			return notFound(err, ErrCourseNotFound)
+		}
+
+		// 2. Ambil/buat student lalu kunci barisnya
+		//    (grant untuk student yang sama jadi berurutan)
+		student, err := findOrCreateStudent(tx, input.AuthID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(student, "id = ?", student.ID).Error; err != nil {
+			return err
+		}
+
+		// 3. Sudah punya enrollment aktif? Perbarui di tempat
+		err = tx.Where("student_id = ? AND course_id = ? AND revoked_at IS NULL", student.ID, input.CourseID).
+			First(&enrollment).Error
+		if err == nil {
+			var columns []string
+			// Masa berlaku hanya diperpanjang, tidak pernah diperpendek
+			if enrollment.ExpiresAt != nil && (input.ExpiresAt == nil || input.ExpiresAt.After(*enrollment.ExpiresAt)) {
+				enrollment.ExpiresAt = input.ExpiresAt
+				columns = append(columns, "expires_at")
+			}
+			// Grant terbaru menentukan asal akses (misal PURCHASE di atas
+			// FREE membawa ID order yang dibutuhkan saat refund)
+			if enrollment.Source != input.Source || enrollment.Reference != input.Reference {
+				enrollment.Source = input.Source
+				enrollment.Reference = input.Reference
+				columns = append(columns, "source", "reference")
+			}
+			if len(columns) == 0 {
+				return nil // Panggilan ulang yang identik
+			}
+			if err := tx.Model(&enrollment).Select(columns).Updates(&enrollment).Error; err != nil {
+				return err
+			}
+			return enqueueEnrollmentEvent(tx, models.EventEnrollmentGranted, &enrollment, input.AuthID)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 4. Buat enrollment baru
+		enrollment = models.Enrollment{
+			StudentID: student.ID,
+			CourseID:  input.CourseID,
+			Source:    input.Source,
+			Reference: input.Reference,
+			GrantedAt: time.Now(),
+			ExpiresAt: input.ExpiresAt,
+		}
+		if err := tx.Create(&enrollment).Error; err != nil {
+			return err
+		}
+		created = true
+
+		return enqueueEnrollmentEvent(tx, models.EventEnrollmentGranted, &enrollment, input.AuthID)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &enrollment, created, nil
+}
+
+// RevokeEnrollment mencabut enrollment aktif (misal karena refund).
+// ErrEnrollmentNotFound jika tidak ada enrollment aktif.
+func (r *courseRepository) RevokeEnrollment(ctx context.Context, authID string, courseID uuid.UUID, reason string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci enrollment aktif
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "enrollments"}}).
+			Joins("JOIN students ON students.id = enrollments.student_id").
+			Where("students.auth_id = ? AND enrollments.course_id = ? AND enrollments.revoked_at IS NULL", authID, courseID).
+			First(&enrollment).Error
+		if err != nil {
+			return notFound(err, ErrEnrollmentNotFound)
+		}
+
+		// 2. Tandai dicabut
+		now := time.Now()
+		enrollment.RevokedAt = &now
+		enrollment.RevokeReason = reason
+		if err := tx.Model(&enrollment).Select("revoked_at", "revoke_reason").Updates(&enrollment).Error; err != nil {
+			return err
+		}
+
+		return enqueueEnrollmentEvent(tx, models.EventEnrollmentRevoked, &enrollment, authID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// GetEnrollment mengambil enrollment user untuk satu course:
+// yang belum dicabut jika ada, jika tidak yang terakhir dicabut.
+// ErrEnrollmentNotFound jika user tidak pernah enroll.
+func (r *courseRepository) GetEnrollment(ctx context.Context, authID string, courseID uuid.UUID) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	err := r.db.WithContext(ctx).
+		Joins("JOIN students ON students.id = enrollments.student_id").
+		Where("students.auth_id = ? AND enrollments.course_id = ?", authID, courseID).
+		Order("enrollments.revoked_at IS NULL DESC, enrollments.granted_at DESC").
+		First(&enrollment).Error
+	if err != nil {
+		return nil, notFound(err, ErrEnrollmentNotFound)
+	}
+	return &enrollment, nil
+}
+
+// IsCourseOwner mengecek apakah 'authID' adalah teacher pemilik course
+// (tanpa membuat profil teacher baru)
+func (r *courseRepository) IsCourseOwner(ctx context.Context, courseID uuid.UUID, authID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Course{}).
+		Joins("JOIN teachers ON teachers.id = courses.teacher_id").
+		Where("courses.id = ? AND teachers.auth_id = ?", courseID, authID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// enqueueEnrollmentEvent menulis event enrollment ke outbox
+func enqueueEnrollmentEvent(tx *gorm.DB, eventType string, enrollment *models.Enrollment, authID string) error {
+	return enqueueEvent(tx, eventType, enrollment.CourseID, map[string]interface{}{
+		"enrollmentId": enrollment.ID,
+		"courseId":     enrollment.CourseID,
+		"authId":       authID,
+		"source":       enrollment.Source,
+		"expiresAt":    enrollment.ExpiresAt,
+		"revokedAt":    enrollment.RevokedAt,
+	})
+}
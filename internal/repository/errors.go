@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+)
+
+// --- Error domain (tipe Error & Kind ada di package apperr) ---
+
+// Tidak ditemukan
+var (
+	ErrCourseNotFound     = apperr.NewError(apperr.KindNotFound, "COURSE_NOT_FOUND", "course not found")
+	ErrChapterNotFound    = apperr.NewError(apperr.KindNotFound, "CHAPTER_NOT_FOUND", "chapter not found")
+	ErrLessonNotFound     = apperr.NewError(apperr.KindNotFound, "LESSON_NOT_FOUND", "lesson not found")
+	ErrCategoryNotFound   = apperr.NewError(apperr.KindNotFound, "CATEGORY_NOT_FOUND", "category not found")
+	ErrTagNotFound        = apperr.NewError(apperr.KindNotFound, "TAG_NOT_FOUND", "tag not found")
+	ErrCouponNotFound     = apperr.NewError(apperr.KindNotFound, "COUPON_NOT_FOUND", "coupon not found or expired")
+	ErrRedemptionNotFound = apperr.NewError(apperr.KindNotFound, "REDEMPTION_NOT_FOUND", "coupon redemption not found")
+	ErrEnrollmentNotFound = apperr.NewError(apperr.KindNotFound, "ENROLLMENT_NOT_FOUND", "active enrollment not found")
+)
+
+// Kepemilikan & transisi
+var (
+	ErrNotOwner          = apperr.NewError(apperr.KindForbidden, "NOT_OWNER", "you do not own this course")
+	ErrInvalidTransition = apperr.NewError(apperr.KindInvalidTransition, "INVALID_STATUS_TRANSITION", "invalid status transition")
+	ErrTransitionRole    = apperr.NewError(apperr.KindForbidden, "STATUS_TRANSITION_FORBIDDEN", "role may not perform this status transition")
+	ErrCourseNotReady    = apperr.NewError(apperr.KindUnprocessable, "COURSE_NOT_READY", "course is not ready for review")
+)
+
+// Konflik
+var (
+	ErrVersionConflict     = apperr.NewError(apperr.KindPreconditionFailed, "VERSION_CONFLICT", "resource was modified by another request")
+	ErrTagNameTaken        = apperr.NewError(apperr.KindConflict, "TAG_NAME_TAKEN", "tag name already exists")
+	ErrCategoryNameTaken   = apperr.NewError(apperr.KindConflict, "CATEGORY_NAME_TAKEN", "category name already exists")
+	ErrCategoryCycle       = apperr.NewError(apperr.KindConflict, "CATEGORY_CYCLE", "category cannot be moved under itself or its descendants")
+	ErrCategoryHasChildren = apperr.NewError(apperr.KindConflict, "CATEGORY_HAS_CHILDREN", "category still has child categories")
+	ErrCouponExhausted     = apperr.NewError(apperr.KindConflict, "COUPON_EXHAUSTED", "coupon has reached its maximum uses")
+	ErrRedemptionReleased  = apperr.NewError(apperr.KindConflict, "REDEMPTION_ALREADY_RELEASED", "coupon redemption already released")
+)
+
+// Aturan bisnis & input
+var (
+	ErrCouponNotApplicable = apperr.NewError(apperr.KindUnprocessable, "COUPON_NOT_APPLICABLE", "coupon does not apply to this course")
+	ErrCourseNotFree       = apperr.NewError(apperr.KindUnprocessable, "COURSE_NOT_FREE", "course is not free")
+	ErrInvalidCurriculum   = apperr.NewError(apperr.KindUnprocessable, "INVALID_CURRICULUM", "curriculum has invalid nodes")
+	ErrLessonMoveCourse    = apperr.NewError(apperr.KindInvalidInput, "LESSON_MOVE_CROSS_COURSE", "lesson can only be moved to a chapter in the same course")
+	ErrTagMergeSelf        = apperr.NewError(apperr.KindInvalidInput, "TAG_MERGE_SELF", "cannot merge a tag into itself")
+	ErrChapterNotInCourse  = apperr.NewError(apperr.KindInvalidInput, "CHAPTER_NOT_IN_COURSE", "chapter not found or does not belong to this course")
+	ErrLessonNotInChapter  = apperr.NewError(apperr.KindInvalidInput, "LESSON_NOT_IN_CHAPTER", "lesson not found or does not belong to this chapter")
+	ErrReorderMismatch     = apperr.NewError(apperr.KindInvalidInput, "REORDER_MISMATCH", "reorder must list every chapter or lesson exactly once")
+)
+
+// notFound menerjemahkan gorm.ErrRecordNotFound menjadi error domain
+// (gorm.ErrRecordNotFound tetap ada di rantai error); error lain diteruskan
+func notFound(err error, domainErr *apperr.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr.Wrap(err)
+	}
+	return err
+}
+
+// duplicate menerjemahkan pelanggaran unique constraint menjadi error domain
+func duplicate(err error, domainErr *apperr.Error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domainErr.Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// enqueueEvent menulis event ke 'outbox_events' memakai 'tx' yang sama
+// dengan mutasi datanya, sehingga event hanya ada jika mutasi di-commit.
+// Pengiriman ke consumer dilakukan oleh outbox relay.
+func enqueueEvent(tx *gorm.DB, eventType string, courseID uuid.UUID, data interface{}) error {
+	now := time.Now()
+	envelope := models.EventEnvelope{
+		ID:          uuid.New(),
+		Type:        eventType,
+		Version:     models.EventEnvelopeVersion,
+		Source:      "course-service",
+		AggregateID: courseID,
+		OccurredAt:  now,
+		Data:        data,
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		ID:            envelope.ID,
+		AggregateID:   courseID,
+		EventType:     eventType,
+		Payload:       payload,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// enqueueCurriculumChanged adalah shortcut untuk event perubahan kurikulum
+func enqueueCurriculumChanged(tx *gorm.DB, courseID uuid.UUID, change string, nodeID uuid.UUID) error {
+	return enqueueEvent(tx, models.EventCourseCurriculumChanged, courseID, map[string]interface{}{
+		"courseId": courseID,
+		"change":   change, // misal: "chapter.created", "lesson.updated"
+		"nodeId":   nodeID,
+	})
+}
+
+// courseIDOfChapter mencari course pemilik chapter (di dalam transaksi)
+func courseIDOfChapter(tx *gorm.DB, chapterID uuid.UUID) (uuid.UUID, error) {
+	var chapter models.Chapter
+	if err := tx.Select("id", "course_id").Where("id = ?", chapterID).First(&chapter).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return chapter.CourseID, nil
+}
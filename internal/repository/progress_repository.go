@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// LessonProgressInput adalah satu laporan posisi dari player
+type LessonProgressInput struct {
+	LessonID        uuid.UUID
+	PositionSeconds int
+	PercentWatched  *float64   // nil = dihitung dari posisi & Lesson.Duration
+	Completed       bool       // Player boleh menandai selesai secara eksplisit
+	RecordedAt      *time.Time // Waktu di sisi player (untuk batch yang datang tidak berurutan)
+}
+
+// UpsertLessonProgress menyimpan satu batch progress dalam satu statement.
+// Aturan merge dengan baris yang sudah ada:
+//   - posisi hanya ditimpa oleh laporan yang lebih baru (RecordedAt)
+//   - persen ditonton tidak pernah turun
+//   - completed_at diisi sekali saja
+func (r *courseRepository) UpsertLessonProgress(ctx context.Context, authID string, inputs []LessonProgressInput) ([]*models.LessonProgress, error) {
+	rows := []*models.LessonProgress{}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		student, err := findOrCreateStudent(tx, authID)
+		if err != nil {
+			return err
+		}
+
+		// 1. Ambil durasi & course untuk semua lesson di batch
+		ids := make([]uuid.UUID, 0, len(inputs))
+		for _, input := range inputs {
+			ids = append(ids, input.LessonID)
+		}
+		var lessons []models.Lesson
+		err = tx.Joins("JOIN chapters ON chapters.id = lessons.chapter_id").
+			Select("lessons.id, lessons.duration, chapters.course_id").
+			Where("lessons.id IN ?", uniqueIDs(ids)).
+			Find(&lessons).Error
+		if err != nil {
+			return err
+		}
+		lessonByID := make(map[uuid.UUID]models.Lesson, len(lessons))
+		for _, lesson := range lessons {
+			lessonByID[lesson.ID] = lesson
+		}
+
+		// 2. Bangun baris; satu lesson muncul sekali per statement
+		//    (laporan yang paling baru yang dipakai)
+		now := time.Now()
+		byLesson := make(map[uuid.UUID]*models.LessonProgress, len(inputs))
+		for _, input := range inputs {
+			lesson, ok := lessonByID[input.LessonID]
+			if !ok {
+				return ErrLessonNotFound.With("lessonId", input.LessonID)
+			}
+
+			recordedAt := now
+			if input.RecordedAt != nil && input.RecordedAt.Before(now) {
+				recordedAt = *input.RecordedAt
+			}
+			if existing, ok := byLesson[lesson.ID]; ok && existing.UpdatedAt.After(recordedAt) {
+				continue
+			}
+
+			row := &models.LessonProgress{
+				ID:              uuid.New(),
+				StudentID:       student.ID,
+				LessonID:        lesson.ID,
+				CourseID:        lesson.CourseID,
+				PositionSeconds: max(input.PositionSeconds, 0),
+				PercentWatched:  models.WatchedPercent(input.PositionSeconds, input.PercentWatched, input.Completed, lesson.Duration),
+				UpdatedAt:       recordedAt,
+			}
+			if models.IsLessonCompleted(row.PercentWatched, input.Completed) {
+				row.CompletedAt = &recordedAt
+			}
+			byLesson[lesson.ID] = row
+		}
+		for _, row := range byLesson {
+			rows = append(rows, row)
+		}
+		// Urutan tetap (per lesson) agar dua batch bersamaan mengunci baris
+		// ON CONFLICT dengan urutan yang sama (tidak deadlock)
+		sort.Slice(rows, func(i, j int) bool {
+			return bytes.Compare(rows[i].LessonID[:], rows[j].LessonID[:]) < 0
+		})
+		if len(rows) == 0 {
+			return nil
+		}
+
+		// 3. Upsert
+		return tx.Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "student_id"}, {Name: "lesson_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"position_seconds": gorm.Expr("CASE WHEN EXCLUDED.updated_at >= lesson_progress.updated_at THEN EXCLUDED.position_seconds ELSE lesson_progress.position_seconds END"),
+					"percent_watched":  gorm.Expr("GREATEST(lesson_progress.percent_watched, EXCLUDED.percent_watched)"),
+					"completed_at":     gorm.Expr("COALESCE(lesson_progress.completed_at, EXCLUDED.completed_at)"),
+					"updated_at":       gorm.Expr("GREATEST(lesson_progress.updated_at, EXCLUDED.updated_at)"),
+				}),
+			},
+			clause.Returning{},
+		).Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetCourseProgress menghitung ringkasan progress student di satu course.
+// ErrCourseNotFound jika course tidak ada.
+func (r *courseRepository) GetCourseProgress(ctx context.Context, authID string, courseID uuid.UUID) (*models.CourseProgressSummary, error) {
+	// 1. Pastikan course ada (kurikulum kosong != course tidak ada)
+	var course models.Course
+	if err := r.db.WithContext(ctx).Select("id").Where("id = ?", courseID).First(&course).Error; err != nil {
+		return nil, notFound(err, ErrCourseNotFound)
+	}
+
+	// 2. Kurikulum terurut
+	var chapters []models.Chapter
+	err := r.db.WithContext(ctx).
+		Preload("Lessons", func(db *gorm.DB) *gorm.DB {
+			return db.Order("lessons.order ASC")
+		}).
+		Where("course_id = ?", courseID).
+		Order("chapters.order ASC").
+		Find(&chapters).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Progress student di course ini
+	progress := []*models.LessonProgress{}
+	err = r.db.WithContext(ctx).
+		Joins("JOIN students ON students.id = lesson_progress.student_id").
+		Where("students.auth_id = ? AND lesson_progress.course_id = ?", authID, courseID).
+		Find(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.SummarizeCourseProgress(courseID, chapters, progress)
+	return &summary, nil
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// Opsi ts_headline untuk snippet (bagian yang cocok dibungkus <mark>)
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// PriceBucket adalah satu rentang harga untuk facet (Max 0 = tanpa batas atas)
+type PriceBucket struct {
+	Key string
+	Min float64
+	Max float64
+}
+
+// PriceBuckets adalah rentang harga yang dipakai facet 'price' (dalam Rupiah)
+var PriceBuckets = []PriceBucket{
+	{Key: "under_100k", Min: 0, Max: 100000},
+	{Key: "100k_250k", Min: 100000, Max: 250000},
+	{Key: "250k_500k", Min: 250000, Max: 500000},
+	{Key: "500k_plus", Min: 500000},
+}
+
+// CourseSearchHit adalah satu hasil pencarian beserta skor & snippet
+type CourseSearchHit struct {
+	*models.Course
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// FacetCount adalah jumlah course untuk satu nilai facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// CourseFacets berisi hitungan facet untuk query yang sama
+type CourseFacets struct {
+	Level    []FacetCount `json:"level"`
+	Category []FacetCount `json:"category"`
+	Tag      []FacetCount `json:"tag"`
+	Price    []FacetCount `json:"price"`
+}
+
+// CourseSearchResult adalah hasil lengkap SearchCourses
+type CourseSearchResult struct {
+	Hits   []CourseSearchHit
+	Total  int64
+	Facets CourseFacets
+}
+
+// searchBase membangun query dasar: full-text + semua filter.
+// Filter kategori/tag memakai EXISTS (bukan JOIN) agar course tidak terduplikasi.
+func (r *courseRepository) searchBase(ctx context.Context, filters CourseFilters) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&models.Course{}).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS tsq", filters.Query).
+		Where("courses.search_vector @@ tsq")
+
+	if len(filters.Status) > 0 {
+		query = query.Where("courses.status IN (?)", filters.Status)
+	}
+	if len(filters.Level) > 0 {
+		query = query.Where("courses.level IN (?)", filters.Level)
+	}
+	if filters.TeacherID != uuid.Nil {
+		query = query.Where("courses.teacher_id = ?", filters.TeacherID)
+	}
+	if len(filters.CategorySlugs) > 0 {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM course_categories cc JOIN categories cat ON cat.id = cc.category_id
+			WHERE cc.course_id = courses.id AND cat.slug IN (?))`, filters.CategorySlugs)
+	}
+	if len(filters.TagSlugs) > 0 {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM course_tags ct JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.course_id = courses.id AND t.slug IN (?))`, filters.TagSlugs)
+	}
+	return query
+}
+
+// SearchCourses menjalankan pencarian full-text (filters.Query) dengan
+// urutan relevansi, snippet ter-highlight, paginasi, dan facet.
+func (r *courseRepository) SearchCourses(ctx context.Context, filters CourseFilters) (*CourseSearchResult, error) {
+	result := &CourseSearchResult{Hits: []CourseSearchHit{}}
+
+	// 1. Hitung total (sebelum paginasi)
+	if err := r.searchBase(ctx, filters).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	// 2. Facet dihitung untuk query yang sama
+	facets, err := r.searchFacets(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	result.Facets = *facets
+
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	// 3. Ambil ID + rank + snippet untuk halaman ini
+	var rows []struct {
+		ID      uuid.UUID
+		Rank    float64
+		Snippet string
+	}
+	offset := (filters.Page - 1) * filters.Limit
+	err = r.searchBase(ctx, filters).
+		Select(`courses.id,
+			ts_rank_cd(courses.search_vector, tsq) AS rank,
+			ts_headline('simple', coalesce(courses.title, '') || ' — ' || coalesce(courses.description, ''), tsq, ?) AS snippet`,
+			searchHeadlineOptions).
+		Order("rank DESC, courses.created_at DESC").
+		Offset(offset).
+		Limit(filters.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	// 4. Muat course (relasi ringan, sama seperti GetCourses) lalu urutkan sesuai rank
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var courses []*models.Course
+	err = r.db.WithContext(ctx).
+		Preload("Teacher", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, name, username")
+		}).
+		Preload("Categories").
+		Preload("Tags").
+		Where("id IN ?", ids).
+		Find(&courses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.Course, len(courses))
+	for _, course := range courses {
+		byID[course.ID] = course
+	}
+	for _, row := range rows {
+		if course, ok := byID[row.ID]; ok {
+			result.Hits = append(result.Hits, CourseSearchHit{Course: course, Rank: row.Rank, Snippet: row.Snippet})
+		}
+	}
+
+	return result, nil
+}
+
+// searchFacets menghitung facet level, kategori, tag dan rentang harga
+func (r *courseRepository) searchFacets(ctx context.Context, filters CourseFilters) (*CourseFacets, error) {
+	facets := &CourseFacets{}
+
+	// Level
+	err := r.searchBase(ctx, filters).
+		Select("courses.level AS value, COUNT(*) AS count").
+		Where("courses.level IS NOT NULL AND courses.level <> ''").
+		Group("courses.level").
+		Order("count DESC").
+		Scan(&facets.Level).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Kategori (20 teratas)
+	err = r.searchBase(ctx, filters).
+		Joins("JOIN course_categories fcc ON fcc.course_id = courses.id").
+		Joins("JOIN categories fcat ON fcat.id = fcc.category_id").
+		Select("fcat.slug AS value, fcat.name AS label, COUNT(DISTINCT courses.id) AS count").
+		Group("fcat.slug, fcat.name").
+		Order("count DESC").
+		Limit(20).
+		Scan(&facets.Category).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Tag (20 teratas)
+	err = r.searchBase(ctx, filters).
+		Joins("JOIN course_tags fct ON fct.course_id = courses.id").
+		Joins("JOIN tags ft ON ft.id = fct.tag_id").
+		Select("ft.slug AS value, ft.name AS label, COUNT(DISTINCT courses.id) AS count").
+		Group("ft.slug, ft.name").
+		Order("count DESC").
+		Limit(20).
+		Scan(&facets.Tag).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Harga: course gratis dihitung terpisah dari bucket
+	var free int64
+	if err := r.searchBase(ctx, filters).Where("courses.is_free = true OR courses.price = 0").Count(&free).Error; err != nil {
+		return nil, err
+	}
+	facets.Price = append(facets.Price, FacetCount{Value: "free", Count: free})
+
+	for _, bucket := range PriceBuckets {
+		query := r.searchBase(ctx, filters).
+			Where("courses.is_free = false AND courses.price > 0").
+			Where("courses.price >= ?", bucket.Min)
+		if bucket.Max > 0 {
+			query = query.Where("courses.price < ?", bucket.Max)
+		}
+
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return nil, err
+		}
+		facets.Price = append(facets.Price, FacetCount{Value: bucket.Key, Count: count})
+	}
+
+	return facets, nil
+}
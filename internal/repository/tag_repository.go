@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// TagWithUsage adalah tag beserta jumlah course yang memakainya
+type TagWithUsage struct {
+	models.Tag
+	UsageCount int64 `json:"usageCount"`
+}
+
+// likeEscaper meng-escape wildcard LIKE dari input user
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// tagsWithUsage adalah query dasar tag + jumlah pemakaian dari 'course_tags'
+func tagsWithUsage(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Tag{}).
+		Select("tags.*, COUNT(ct.course_id) AS usage_count").
+		Joins("LEFT JOIN course_tags ct ON ct.tag_id = tags.id").
+		Group("tags.id")
+}
+
+// ✅
+// CreateTag membuat tag baru
+// ('tag.Slug' harus sudah di-set oleh handler)
+func (r *courseRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	return duplicate(r.db.WithContext(ctx).Create(tag).Error, ErrTagNameTaken)
+}
+
+// GetTagByID mengambil satu tag
+func (r *courseRepository) GetTagByID(ctx context.Context, tagID uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.WithContext(ctx).Where("id = ?", tagID).First(&tag).Error
+	if err != nil {
+		return nil, notFound(err, ErrTagNotFound)
+	}
+	return &tag, nil
+}
+
+// GetTags mengambil daftar tag (urut nama) dengan paginasi
+func (r *courseRepository) GetTags(ctx context.Context, page, limit int) ([]*TagWithUsage, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.Tag{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	tags := []*TagWithUsage{}
+	offset := (page - 1) * limit
+	err := tagsWithUsage(r.db.WithContext(ctx)).
+		Order("tags.name ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&tags).Error
+
+	return tags, total, err
+}
+
+// RenameTag memperbarui nama tag
+// (Slug tidak diubah agar filter ?tag=slug yang sudah ada tetap jalan)
+func (r *courseRepository) RenameTag(ctx context.Context, tag *models.Tag) (*models.Tag, error) {
+	err := r.db.WithContext(ctx).
+		Model(tag).
+		Select("name").
+		Updates(tag).Error
+	if err != nil {
+		return nil, duplicate(err, ErrTagNameTaken)
+	}
+	return tag, nil
+}
+
+// SuggestTags mencari tag berdasarkan awalan nama/slug,
+// diurutkan dari yang paling banyak dipakai (untuk autocomplete)
+func (r *courseRepository) SuggestTags(ctx context.Context, prefix string, limit int) ([]*TagWithUsage, error) {
+	pattern := likeEscaper.Replace(prefix) + "%"
+
+	tags := []*TagWithUsage{}
+	err := tagsWithUsage(r.db.WithContext(ctx)).
+		Where("tags.name ILIKE ? OR tags.slug ILIKE ?", pattern, pattern).
+		Order("usage_count DESC, tags.name ASC").
+		Limit(limit).
+		Scan(&tags).Error
+
+	return tags, err
+}
+
+// MergeTags memindahkan semua relasi course dari tag duplikat (source)
+// ke tag kanonik (target), lalu menghapus tag duplikat. Semuanya
+// dalam satu transaksi. Mengembalikan jumlah course yang dipindah.
+func (r *courseRepository) MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) (int64, error) {
+	if sourceID == targetID {
+		return 0, ErrTagMergeSelf
+	}
+
+	var moved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. Kunci kedua tag (sekaligus memastikan keduanya ada)
+		var tags []models.Tag
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{sourceID, targetID}).
+			Find(&tags).Error
+		if err != nil {
+			return err
+		}
+		if len(tags) != 2 {
+			return ErrTagNotFound
+		}
+
+		// 2. Salin relasi ke tag kanonik
+		//    (course yang sudah punya kedua tag tidak diduplikasi)
+		result := tx.Exec(`
+			INSERT INTO course_tags (course_id, tag_id)
+			SELECT course_id, ? FROM course_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		// 3. Hapus relasi lama & tag duplikat
+		if err := tx.Exec("DELETE FROM course_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", sourceID).Delete(&models.Tag{}).Error
+	})
+
+	return moved, err
+}
+
+// IsTagSlugInUse mengecek keunikan slug tag
+func (r *courseRepository) IsTagSlugInUse(ctx context.Context, slug string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Tag{}).Where("slug = ?", slug).Count(&count).Error
+	if err != nil {
+		return true, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork menjalankan beberapa operasi repository dalam satu transaksi.
+// Dipakai service untuk operasi multi-langkah (misal: resolve teacher +
+// slug + create course) agar semuanya commit atau rollback bersama.
+type UnitOfWork interface {
+	// Do memanggil fn dengan repository yang terikat ke transaksi.
+	// Commit jika fn mengembalikan nil, rollback jika error (atau panic).
+	// Semua panggilan di dalam fn WAJIB memakai 'ctx' & 'repo' yang diberikan.
+	// Do di dalam Do memakai transaksi yang sama (savepoint).
+	Do(ctx context.Context, fn func(ctx context.Context, repo ICourseRepository) error) error
+}
+
+// Decorator membungkus repository (misal cache) untuk dipakai di dalam
+// transaksi, sama seperti repository di luar transaksi
+type Decorator func(ICourseRepository) ICourseRepository
+
+type gormUnitOfWork struct {
+	db         *gorm.DB
+	decorators []Decorator
+}
+
+// NewUnitOfWork membuat UnitOfWork di atas koneksi GORM
+func NewUnitOfWork(db *gorm.DB, decorators ...Decorator) UnitOfWork {
+	return &gormUnitOfWork{db: db, decorators: decorators}
+}
+
+// unitOfWorkState disimpan di context selama transaksi berjalan
+type unitOfWorkState struct {
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+type unitOfWorkKey struct{}
+
+func (u *gormUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repo ICourseRepository) error) error {
+	// 1. Sudah di dalam transaksi: pakai savepoint di transaksi yang sama
+	if state, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWorkState); ok {
+		return state.tx.Transaction(func(tx *gorm.DB) error {
+			return fn(ctx, u.repository(tx))
+		})
+	}
+
+	// 2. Transaksi baru; hook afterCommit dijalankan setelah commit berhasil
+	state := &unitOfWorkState{}
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, unitOfWorkKey{}, state), u.repository(tx))
+	})
+	if err != nil {
+		return err
+	}
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+func (u *gormUnitOfWork) repository(tx *gorm.DB) ICourseRepository {
+	repo := NewCourseRepository(tx)
+	for _, decorate := range u.decorators {
+		repo = decorate(repo)
+	}
+	return repo
+}
+
+// inUnitOfWork: ctx berada di dalam transaksi UnitOfWork
+func inUnitOfWork(ctx context.Context) bool {
+	_, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWorkState)
+	return ok
+}
+
+// afterCommit menunda fn sampai transaksi UnitOfWork di ctx di-commit
+// (tidak dijalankan jika rollback). Di luar UnitOfWork fn langsung dijalankan.
+func afterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWorkState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockDB membuka GORM (dialek Postgres) di atas sqlmock, tanpa log query
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db, mock
+}
+
+// newMockUnitOfWork membuat UnitOfWork di atas sqlmock (hanya BEGIN,
+// SAVEPOINT, COMMIT & ROLLBACK yang diharapkan)
+func newMockUnitOfWork(t *testing.T) (UnitOfWork, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock := newMockDB(t)
+	return NewUnitOfWork(db), mock
+}
+
+func TestAfterCommitOutsideUnitOfWorkRunsImmediately(t *testing.T) {
+	ran := false
+	afterCommit(context.Background(), func() { ran = true })
+	if !ran {
+		t.Fatal("hook outside a unit of work did not run immediately")
+	}
+	if inUnitOfWork(context.Background()) {
+		t.Fatal("background context reported as inside a unit of work")
+	}
+}
+
+func TestUnitOfWorkAfterCommit(t *testing.T) {
+	errFailed := errors.New("step failed")
+
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context, uow UnitOfWork, log *[]string) error
+		expect    func(mock sqlmock.Sqlmock)
+		wantErr   error
+		wantHooks []string
+	}{
+		{
+			name: "hooks run after commit in registration order",
+			fn: func(ctx context.Context, uow UnitOfWork, log *[]string) error {
+				afterCommit(ctx, func() { *log = append(*log, "invalidate cache") })
+				afterCommit(ctx, func() { *log = append(*log, "publish event") })
+				if len(*log) != 0 {
+					t.Error("hook ran before commit")
+				}
+				return nil
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			wantHooks: []string{"invalidate cache", "publish event"},
+		},
+		{
+			name: "hooks are dropped on rollback",
+			fn: func(ctx context.Context, uow UnitOfWork, log *[]string) error {
+				afterCommit(ctx, func() { *log = append(*log, "invalidate cache") })
+				return errFailed
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErr: errFailed,
+		},
+		{
+			name: "nested unit of work defers to the outer commit",
+			fn: func(ctx context.Context, uow UnitOfWork, log *[]string) error {
+				err := uow.Do(ctx, func(ctx context.Context, repo ICourseRepository) error {
+					afterCommit(ctx, func() { *log = append(*log, "inner") })
+					return nil
+				})
+				if len(*log) != 0 {
+					t.Error("nested hook ran before the outer commit")
+				}
+				return err
+			},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantHooks: []string{"inner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uow, mock := newMockUnitOfWork(t)
+			tt.expect(mock)
+
+			var hooks []string
+			err := uow.Do(context.Background(), func(ctx context.Context, repo ICourseRepository) error {
+				if !inUnitOfWork(ctx) {
+					t.Error("context inside Do is not marked as a unit of work")
+				}
+				return tt.fn(ctx, uow, &hooks)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(hooks, tt.wantHooks) {
+				t.Fatalf("hooks = %v, want %v", hooks, tt.wantHooks)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -1,72 +1,163 @@
 package routes
 
 import (
-	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/wtppaul/course-service/internal/handler"
 	"github.com/wtppaul/course-service/internal/middleware"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/observability"
 )
 
-// SetupCourseRoutes merakit semua rute untuk service ini
-func SetupCourseRoutes(router *gin.Engine, courseHandler *handler.CourseHandler) {
+// IdempotencyTTL adalah lama respons disimpan untuk replay Idempotency-Key
+const IdempotencyTTL = 24 * time.Hour
+
+// SetupCourseRoutes merakit semua rute untuk service ini.
+// Setiap rute di /internal mendeklarasikan policy otorisasinya di sini.
+// metrics nil = metrik dimatikan (tanpa /metrics).
+func SetupCourseRoutes(router *gin.Engine, courseHandler *handler.CourseHandler, healthHandler *handler.HealthHandler, internalAuth gin.HandlerFunc, authorizer *middleware.Authorizer, metrics *observability.Metrics, redisClient *redis.Client) {
+	// Middleware global (urutan penting, dipasang sebelum rute didaftarkan):
+	// request ID & logger -> tracing & metrik -> access log -> recovery
+	router.Use(
+		middleware.RequestIDMiddleware(),
+		observability.Middleware(metrics),
+		middleware.AccessLogMiddleware(),
+		middleware.RecoveryMiddleware(),
+	)
+
+	allow := authorizer.Require
+
+	// Policy yang dipakai berulang
+	var (
+		public         = allow(middleware.Public())
+		authenticated  = allow(middleware.Authenticated())
+		userOrService  = allow(middleware.UserOrService("authId"))
+		courseVisible  = allow(middleware.PublishedOr(middleware.CourseParam("id"), models.RoleCurator, models.RoleAdmin))
+		courseOwner    = allow(middleware.CourseOwnerOr(middleware.CourseParam("id"), models.RoleAdmin))
+		courseReviewer = allow(middleware.CourseOwnerOr(middleware.CourseParam("id"), models.RoleCurator, models.RoleAdmin))
+		chapterOwner   = allow(middleware.CourseOwnerOr(middleware.ChapterParam("chapterId"), models.RoleAdmin))
+		lessonOwner    = allow(middleware.CourseOwnerOr(middleware.LessonParam("lessonId"), models.RoleAdmin))
+		adminOnly      = allow(middleware.AnyRole(models.RoleAdmin))
+		curatorOrAdmin = allow(middleware.AnyRole(models.RoleCurator, models.RoleAdmin))
+		serviceOrAdmin = allow(middleware.ServiceOrRole(models.RoleAdmin))
+	)
 
 	// Grup /internal dilindungi oleh middleware
 	// Ini adalah service "bodoh", tidak ada rute publik
 	internal := router.Group("/internal")
-	internal.Use(middleware.InternalAuthMiddleware())
+	internal.Use(internalAuth)
+	internal.Use(middleware.IdempotencyMiddleware(redisClient, IdempotencyTTL)) // Retry BFF aman (header Idempotency-Key)
 	{
 		// Rute yang berpusat pada Course
 		courses := internal.Group("/courses")
 		{
-			courses.POST("", courseHandler.CreateCourse)                 		// POST /internal/courses
-			courses.GET("", courseHandler.GetCourses) 											// GET /internal/courses?status=...
-			courses.GET("/public", courseHandler.GetPublishedCourses) 			// GET /internal/courses/public
-			courses.GET("/slug/:slug", courseHandler.GetCourseBySlug)    		// GET /internal/courses/slug/nama-slug
-			courses.GET("/:id", courseHandler.GetCourseById)            		// GET /internal/courses/uuid
-			courses.PATCH("/:id", courseHandler.UpdateCourse)           		// PATCH /internal/courses/uuid
-			courses.PATCH("/:id/status", courseHandler.UpdateCourseStatus) 	// PATCH /internal/courses/uuid/status
-			courses.PATCH("/:id/tags", courseHandler.UpdateCourseTags) 			// PATCH /internal/courses/uuid/tags
-
-			courses.POST("/:courseId/chapters", courseHandler.CreateChapter)// POST /internal/courses/:courseId/chapters
-			courses.PATCH("/:courseId/chapters/:chapterId", courseHandler.UpdateChapter) 	// PATCH /internal/courses/:courseId/chapters/:chapterId
-			courses.POST("/:courseId/chapters/reorder", courseHandler.ReorderChapters) 		// POST /internal/courses/:courseId/chapters/reorder
-			courses.DELETE("/:courseId/chapters/:chapterId", courseHandler.DeleteChapter) // DELETE /internal/courses/:courseId/chapters/:chapterId
+			courses.POST("", allow(middleware.AnyRole(models.RoleTeacher)), courseHandler.CreateCourse) // POST /internal/courses
+			courses.GET("", public, courseHandler.GetCourses) 											// GET /internal/courses?status=... (selain PUBLISHED: curator/admin)
+			courses.GET("/public", public, courseHandler.GetPublishedCourses) 			// GET /internal/courses/public
+			courses.GET("/slug/:slug", allow(middleware.PublishedOr(middleware.CourseSlugParam("slug"), models.RoleCurator, models.RoleAdmin)), courseHandler.GetCourseBySlug) // GET /internal/courses/slug/nama-slug
+			courses.GET("/:id", courseVisible, courseHandler.GetCourseById)            		// GET /internal/courses/uuid
+			courses.PATCH("/:id", courseOwner, courseHandler.UpdateCourse)           		// PATCH /internal/courses/uuid
+			// Curator hanya boleh transisi review (diatur oleh StatusPolicy)
+			courses.PATCH("/:id/status", courseReviewer, courseHandler.UpdateCourseStatus) 	// PATCH /internal/courses/uuid/status
+			courses.GET("/:id/status-history", courseReviewer, courseHandler.GetCourseStatusHistory) // GET /internal/courses/uuid/status-history
+			courses.GET("/:id/readiness", courseReviewer, courseHandler.GetCourseReadiness) 	// GET /internal/courses/uuid/readiness
+			courses.PATCH("/:id/tags", courseOwner, courseHandler.UpdateCourseTags) 			// PATCH /internal/courses/uuid/tags
+			courses.PATCH("/:id/categories", courseOwner, courseHandler.UpdateCourseCategories) // PATCH /internal/courses/uuid/categories
+
+			courses.POST("/:id/chapters", courseOwner, courseHandler.CreateChapter)// POST /internal/courses/:id/chapters
+			courses.PATCH("/:id/chapters/:chapterId", courseOwner, courseHandler.UpdateChapter) 	// PATCH /internal/courses/:id/chapters/:chapterId
+			courses.POST("/:id/chapters/reorder", courseOwner, courseHandler.ReorderChapters) 		// POST /internal/courses/:id/chapters/reorder
+			courses.DELETE("/:id/chapters/:chapterId", courseOwner, courseHandler.DeleteChapter) // DELETE /internal/courses/:id/chapters/:chapterId
+			courses.PUT("/:id/curriculum", courseOwner, courseHandler.SaveCurriculum)            // PUT /internal/courses/:id/curriculum
 			
 			// Endpoint pricing untuk Payment-service
-			// courses.GET("/:id/pricing", courseHandler.GetPricingDetails)
+			courses.GET("/:id/pricing", public, courseHandler.GetPricingDetails)    // GET /internal/courses/uuid/pricing
+
+			// Cek akses lesson & progress: user untuk dirinya sendiri, BFF/service
+			// (atau admin) untuk user lain lewat 'authId'
+			courses.GET("/:id/access", userOrService, courseHandler.GetCourseAccess)     // GET /internal/courses/uuid/access?authId=...&lessonId=...
+			courses.GET("/:id/progress", userOrService, courseHandler.GetCourseProgress) // GET /internal/courses/uuid/progress?authId=...
 		}
 
 		// Rute yang berpusat pada Teacher
 		teachers := internal.Group("/teachers")
 		{
 			// GET /internal/teachers/uuid/courses
-			teachers.GET("/:teacherId/courses", courseHandler.GetCoursesByTeacherID)
+			teachers.GET("/:teacherId/courses", allow(middleware.TeacherSelfOr("teacherId", models.RoleCurator, models.RoleAdmin)), courseHandler.GetCoursesByTeacherID)
+		}
+
+		// --- GRUP CATEGORY (Admin) ---
+		categories := internal.Group("/categories")
+		{
+			categories.GET("/tree", public, courseHandler.GetCategoryTree)        // GET /internal/categories/tree
+			categories.POST("", adminOnly, courseHandler.CreateCategory)          // POST /internal/categories
+			categories.PATCH("/:id", adminOnly, courseHandler.UpdateCategory)     // PATCH /internal/categories/uuid
+			categories.POST("/:id/move", adminOnly, courseHandler.MoveCategory)   // POST /internal/categories/uuid/move
+			categories.DELETE("/:id", adminOnly, courseHandler.DeleteCategory)    // DELETE /internal/categories/uuid
+		}
+
+		// --- GRUP TAG ---
+		tags := internal.Group("/tags")
+		{
+			tags.GET("", public, courseHandler.GetTags)                  // GET /internal/tags
+			tags.GET("/suggest", public, courseHandler.SuggestTags)      // GET /internal/tags/suggest?q=go
+			tags.POST("", allow(middleware.AnyRole(models.RoleTeacher, models.RoleCurator, models.RoleAdmin)), courseHandler.CreateTag) // POST /internal/tags
+			tags.PATCH("/:id", curatorOrAdmin, courseHandler.RenameTag)     // PATCH /internal/tags/uuid
+			tags.POST("/:id/merge", curatorOrAdmin, courseHandler.MergeTag) // POST /internal/tags/uuid/merge
 		}
 
 		// --- GRUP CHAPTER ---
 		chapters := internal.Group("/chapters")
 		{
 			// POST /internal/chapters/:chapterId/lessons
-			chapters.POST("/:chapterId/lessons", courseHandler.CreateLesson)
+			chapters.POST("/:chapterId/lessons", chapterOwner, courseHandler.CreateLesson)
+			// POST /internal/chapters/:chapterId/lessons/reorder
+			chapters.POST("/:chapterId/lessons/reorder", chapterOwner, courseHandler.ReorderLessons)
 		}
 
 		// --- GRUP LESSON ---
 		lessons := internal.Group("/lessons")
 		{
 			// PATCH /internal/lessons/:lessonId
-			lessons.PATCH("/:lessonId", courseHandler.UpdateLesson)
+			lessons.PATCH("/:lessonId", lessonOwner, courseHandler.UpdateLesson)
+			// DELETE /internal/lessons/:lessonId
+			lessons.DELETE("/:lessonId", lessonOwner, courseHandler.DeleteLesson)
+			// POST /internal/lessons/:lessonId/move
+			lessons.POST("/:lessonId/move", lessonOwner, courseHandler.MoveLesson)
 		}
 
 
-		// coupons := internal.Group("/coupons")
-		// {
-		// 	coupons.POST("/validate", courseHandler.ValidateCoupon)
-		// }
+		// --- GRUP ENROLLMENT (dipanggil oleh Payment-service) ---
+		enrollments := internal.Group("/enrollments")
+		{
+			enrollments.POST("", serviceOrAdmin, courseHandler.GrantEnrollment)         // POST /internal/enrollments
+			enrollments.POST("/revoke", serviceOrAdmin, courseHandler.RevokeEnrollment) // POST /internal/enrollments/revoke
+		}
+
+		// --- GRUP PROGRESS (dipanggil oleh player via BFF) ---
+		progress := internal.Group("/progress")
+		{
+			progress.POST("/batch", authenticated, courseHandler.UpsertLessonProgress) // POST /internal/progress/batch
+		}
+
+		// --- GRUP COUPON (dipanggil oleh Payment-service) ---
+		coupons := internal.Group("/coupons")
+		{
+			coupons.POST("/validate", public, courseHandler.ValidateCoupon)       // POST /internal/coupons/validate
+			coupons.POST("/redeem", serviceOrAdmin, courseHandler.RedeemCoupon)   // POST /internal/coupons/redeem
+			coupons.POST("/release", serviceOrAdmin, courseHandler.ReleaseCoupon) // POST /internal/coupons/release
+		}
 	}
 	
-	// Rute Health Check Sederhana (Publik)
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "UP", "service": "course-service"})
-	})
-}
\ No newline at end of file
+	// Probe Kubernetes (Publik, tanpa signature)
+	router.GET("/livez", healthHandler.Livez)   // Liveness: proses hidup
+	router.GET("/readyz", healthHandler.Readyz) // Readiness: Postgres & Redis siap
+	router.GET("/health", healthHandler.Livez)  // Alias lama untuk /livez
+
+	// Metrik Prometheus (di-scrape dari dalam cluster)
+	if metrics != nil {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+}
@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/handler"
+	"github.com/wtppaul/course-service/internal/middleware"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+	"github.com/wtppaul/course-service/internal/service"
+)
+
+var testSigningKeys = middleware.SigningKeys{"bff-1": []byte("s3cret")}
+
+// routeTestRepo hanya mengimplementasikan method yang dipakai rute akses &
+// progress; method lain panic lewat interface nil yang di-embed
+type routeTestRepo struct {
+	repository.ICourseRepository
+	course     *models.Course
+	enrollment *models.Enrollment // Milik "student-1"
+}
+
+func (r *routeTestRepo) GetCourseByID(ctx context.Context, courseID uuid.UUID) (*models.Course, error) {
+	if courseID != r.course.ID {
+		return nil, repository.ErrCourseNotFound
+	}
+	return r.course, nil
+}
+
+func (r *routeTestRepo) IsCourseOwner(ctx context.Context, courseID uuid.UUID, authID string) (bool, error) {
+	return false, nil
+}
+
+func (r *routeTestRepo) GetEnrollment(ctx context.Context, authID string, courseID uuid.UUID) (*models.Enrollment, error) {
+	if authID != "student-1" {
+		return nil, repository.ErrEnrollmentNotFound
+	}
+	return r.enrollment, nil
+}
+
+func (r *routeTestRepo) GetCourseProgress(ctx context.Context, authID string, courseID uuid.UUID) (*models.CourseProgressSummary, error) {
+	return &models.CourseProgressSummary{CourseID: courseID}, nil
+}
+
+// newTestRouter merakit semua rute di atas routeTestRepo (tanpa Redis & metrik)
+func newTestRouter(t *testing.T, repo repository.ICourseRepository) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	policy, err := models.NewStatusPolicy("")
+	if err != nil {
+		t.Fatalf("NewStatusPolicy: %v", err)
+	}
+	svc := service.NewCourseService(repo, nil, policy, models.ReadinessConfig{}, nil)
+
+	router := gin.New()
+	SetupCourseRoutes(router,
+		handler.NewCourseHandler(svc),
+		handler.NewHealthHandler(nil, nil, 0),
+		middleware.InternalAuthMiddleware(testSigningKeys, time.Minute, 0),
+		middleware.NewAuthorizer(repo),
+		nil, nil,
+	)
+	return router
+}
+
+// signedGet membuat GET bertanda tangan "bff-1"; userID kosong = panggilan service
+func signedGet(uri, userID, userRole string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, uri, nil)
+	for name, value := range middleware.SignatureHeaders("bff-1", testSigningKeys["bff-1"], http.MethodGet, uri, nil, userID, userRole, time.Now()) {
+		req.Header.Set(name, value)
+	}
+	if userID != "" {
+		req.Header.Set(middleware.HeaderUserID, userID)
+		req.Header.Set(middleware.HeaderUserRole, userRole)
+	}
+	return req
+}
+
+func TestAccessAndProgressRoutes(t *testing.T) {
+	course := &models.Course{ID: uuid.New(), Status: models.StatusPublished}
+	repo := &routeTestRepo{
+		course:     course,
+		enrollment: &models.Enrollment{CourseID: course.ID, Source: models.EnrollmentPurchase},
+	}
+	router := newTestRouter(t, repo)
+	base := "/internal/courses/" + course.ID.String()
+
+	tests := []struct {
+		name        string
+		uri         string
+		userID      string
+		userRole    string
+		wantStatus  int
+		wantAuthID  string
+		wantAllowed *bool
+	}{
+		{name: "service access for named user", uri: base + "/access?authId=student-1", wantStatus: http.StatusOK, wantAuthID: "student-1", wantAllowed: ptr(true)},
+		{name: "service access for user without enrollment", uri: base + "/access?authId=student-2", wantStatus: http.StatusOK, wantAuthID: "student-2", wantAllowed: ptr(false)},
+		{name: "service access without subject", uri: base + "/access", wantStatus: http.StatusUnauthorized},
+		{name: "service progress for named user", uri: base + "/progress?authId=student-1", wantStatus: http.StatusOK},
+		{name: "service progress without subject", uri: base + "/progress", wantStatus: http.StatusUnauthorized},
+		{name: "user access for self", uri: base + "/access", userID: "student-1", userRole: "STUDENT", wantStatus: http.StatusOK, wantAuthID: "student-1", wantAllowed: ptr(true)},
+		{name: "user cannot check another user", uri: base + "/access?authId=student-2", userID: "student-1", userRole: "STUDENT", wantStatus: http.StatusOK, wantAuthID: "student-1", wantAllowed: ptr(true)},
+		{name: "admin checks another user", uri: base + "/access?authId=student-2", userID: "admin-1", userRole: "ADMIN", wantStatus: http.StatusOK, wantAuthID: "student-2", wantAllowed: ptr(false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, signedGet(tt.uri, tt.userID, tt.userRole))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantAllowed == nil {
+				return
+			}
+
+			var body struct {
+				AuthID  string `json:"authId"`
+				Allowed bool   `json:"allowed"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.AuthID != tt.wantAuthID || body.Allowed != *tt.wantAllowed {
+				t.Fatalf("access = {authId:%s allowed:%v}, want {authId:%s allowed:%v}", body.AuthID, body.Allowed, tt.wantAuthID, *tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,238 @@
+// Package service berisi aturan bisnis course-service (slug, resolve
+// teacher, default course baru, transisi status, harga, akses) yang
+// tidak bergantung pada transport. Handler HTTP (gin), gRPC maupun CLI
+// cukup menerjemahkan request ke input bertipe di sini.
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/logging"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/observability"
+	"github.com/wtppaul/course-service/internal/repository"
+	"github.com/wtppaul/course-service/internal/utils"
+)
+
+// CourseService adalah lapisan di antara transport dan ICourseRepository.
+// Error yang dikembalikan adalah error domain (*apperr.Error) atau
+// error model (StatusTransitionError, dll.) yang dipetakan oleh transport.
+type CourseService struct {
+	repo         repository.ICourseRepository
+	uow          repository.UnitOfWork
+	statusPolicy *models.StatusPolicy
+	readiness    models.ReadinessConfig
+	metrics      *observability.Metrics // Counter bisnis (nil = dimatikan)
+}
+
+func NewCourseService(repo repository.ICourseRepository, uow repository.UnitOfWork, statusPolicy *models.StatusPolicy, readiness models.ReadinessConfig, metrics *observability.Metrics) *CourseService {
+	return &CourseService{repo: repo, uow: uow, statusPolicy: statusPolicy, readiness: readiness, metrics: metrics}
+}
+
+// --- Course ---
+
+// CreateCourseInput: course baru milik user 'AuthID' (teacher)
+type CreateCourseInput struct {
+	AuthID string
+	Title  string
+}
+
+// CreateCourse membuat course DRAFT (lisensi NT) untuk teacher pemanggil.
+// Profil teacher, slug dan course dibuat dalam satu transaksi.
+func (s *CourseService) CreateCourse(ctx context.Context, input CreateCourseInput) (*models.Course, error) {
+	var course *models.Course
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		// 1. Tukar AuthID dengan profil teacher (dibuat jika belum ada)
+		teacher, err := repo.FindOrCreateTeacherByAuthID(ctx, input.AuthID)
+		if err != nil {
+			return err
+		}
+
+		// 2. Slug unik
+		slug, err := utils.GenerateUniqueSlug(ctx, input.Title, repo)
+		if err != nil {
+			return err
+		}
+
+		// 3. Simpan dengan nilai default
+		course = &models.Course{
+			Title:     input.Title,
+			Slug:      slug,
+			TeacherID: teacher.ID,
+			Status:    models.StatusDraft,
+			License:   models.LicenseNT,
+		}
+		return repo.CreateCourse(ctx, course)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.CourseCreated()
+	return course, nil
+}
+
+// UpdateCourseTags mengganti seluruh tag course (kosong = semua tag dilepas)
+func (s *CourseService) UpdateCourseTags(ctx context.Context, courseID uuid.UUID, tagIDs []uuid.UUID) error {
+	return s.repo.UpdateCourseTags(ctx, courseID, tagIDs)
+}
+
+// UpdateCourse menyimpan perubahan field course (compare-and-swap pada
+// input.Version). Versi usang -> *VersionConflictError.
+func (s *CourseService) UpdateCourse(ctx context.Context, courseID uuid.UUID, input repository.UpdateCourseInput) (*models.Course, error) {
+	course, err := s.repo.UpdateCourse(ctx, courseID, input)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		if current, err := s.repo.GetCourseDetails(ctx, courseID); err == nil {
+			return nil, &VersionConflictError{Version: current.Version, Current: current}
+		}
+	}
+	return course, err
+}
+
+// --- Bacaan ---
+
+// CourseBySlug mengambil detail course (chapter, lesson, kategori, tag) via slug
+func (s *CourseService) CourseBySlug(ctx context.Context, slug string) (*models.Course, error) {
+	return s.repo.GetCourseBySlug(ctx, slug)
+}
+
+// CourseDetails mengambil detail course via ID
+func (s *CourseService) CourseDetails(ctx context.Context, courseID uuid.UUID) (*models.Course, error) {
+	return s.repo.GetCourseDetails(ctx, courseID)
+}
+
+// PublishedCourses mengambil satu halaman course PUBLISHED
+func (s *CourseService) PublishedCourses(ctx context.Context, page, limit int) ([]*models.Course, error) {
+	return s.repo.GetPublishedCourses(ctx, page, limit)
+}
+
+// ListCourses mengambil course sesuai filter beserta total untuk paginasi
+func (s *CourseService) ListCourses(ctx context.Context, filters repository.CourseFilters) ([]*models.Course, int64, error) {
+	return s.repo.GetCourses(ctx, filters)
+}
+
+// SearchCourses menjalankan pencarian full-text (urut relevansi + facet)
+func (s *CourseService) SearchCourses(ctx context.Context, filters repository.CourseFilters) (*repository.CourseSearchResult, error) {
+	return s.repo.SearchCourses(ctx, filters)
+}
+
+// TeacherCourses mengambil semua course teacher (termasuk draft)
+func (s *CourseService) TeacherCourses(ctx context.Context, teacherID uuid.UUID) ([]*models.Course, error) {
+	return s.repo.GetCoursesByTeacherID(ctx, teacherID)
+}
+
+// --- Status ---
+
+// ChangeStatusInput adalah permintaan perubahan status oleh 'ActorAuthID'
+// dengan role 'Roles' (transisi yang boleh diatur oleh StatusPolicy).
+// IsOwner menentukan role mana yang berlaku (lihat StatusPolicy.CheckActor).
+type ChangeStatusInput struct {
+	CourseID    uuid.UUID
+	Status      models.CourseStatus
+	Reason      string // Catatan reviewer (opsional)
+	ActorAuthID string
+	Roles       []models.Role
+	IsOwner     bool // Pemanggil adalah teacher pemilik course
+}
+
+// ChangeStatus memvalidasi & menyimpan transisi status.
+// Pengajuan ke review (PENDING_REVIEW) harus lolos cek readiness; jika
+// gagal, course ditandai INCOMPLETE dan error COURSE_NOT_READY membawa
+// 'readiness' & 'markedIncomplete'.
+func (s *CourseService) ChangeStatus(ctx context.Context, input ChangeStatusInput) error {
+	// 1. Tolak status yang tidak dikenal (misal typo) sebelum menyentuh DB
+	if !input.Status.IsValid() {
+		return ErrInvalidStatus.With("status", input.Status).With("allowedStatuses", models.AllCourseStatuses)
+	}
+
+	// 2. Transisi & readiness divalidasi di dalam transaksi, setelah baris
+	//    course dikunci: perubahan kurikulum bersamaan menunggu sampai selesai
+	change := repository.CourseStatusChange{
+		Status:      input.Status,
+		ActorAuthID: input.ActorAuthID,
+		Reason:      input.Reason,
+	}
+	var previous models.CourseStatus
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		return repo.UpdateCourseStatus(ctx, input.CourseID, change, func(current models.CourseStatus) error {
+			previous = current
+			if err := s.statusPolicy.CheckActor(current, input.Status, input.Roles, input.IsOwner); err != nil {
+				return err
+			}
+			if input.Status != models.StatusPending {
+				return nil
+			}
+			course, err := repo.GetCourseDetails(ctx, input.CourseID)
+			if err != nil {
+				return err
+			}
+			if report := models.CheckReadiness(course, s.readiness); !report.Ready {
+				return &models.ReadinessError{Report: report}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		var readinessErr *models.ReadinessError
+		if errors.As(err, &readinessErr) {
+			return repository.ErrCourseNotReady.Wrap(err).
+				With("readiness", readinessErr.Report).
+				With("markedIncomplete", s.markIncomplete(ctx, input, readinessErr.Report))
+		}
+		return err
+	}
+
+	s.metrics.StatusTransition(string(previous), string(input.Status))
+	return nil
+}
+
+// markIncomplete memindahkan course ke INCOMPLETE setelah pengajuan gagal
+// cek readiness. Mengembalikan true jika status berubah.
+func (s *CourseService) markIncomplete(ctx context.Context, input ChangeStatusInput, report *models.ReadinessReport) bool {
+	change := repository.CourseStatusChange{
+		Status:      models.StatusIncomplete,
+		ActorAuthID: input.ActorAuthID,
+		Reason:      "Readiness check failed: " + strings.Join(report.FailedRules(), ", "),
+	}
+	var previous models.CourseStatus
+	err := s.repo.UpdateCourseStatus(ctx, input.CourseID, change, func(current models.CourseStatus) error {
+		previous = current
+		return s.statusPolicy.CheckActor(current, models.StatusIncomplete, input.Roles, input.IsOwner)
+	})
+	if err != nil {
+		// Sudah INCOMPLETE, atau transisi tidak diizinkan dari status saat ini
+		var transitionErr *models.StatusTransitionError
+		if !errors.As(err, &transitionErr) {
+			logging.FromContext(ctx).ErrorContext(ctx, "failed to mark course incomplete", "error", err, "course_id", input.CourseID)
+		}
+		return false
+	}
+	s.metrics.StatusTransition(string(previous), string(models.StatusIncomplete))
+	return true
+}
+
+// IsCourseOwner mengecek apakah 'authID' adalah teacher pemilik course
+func (s *CourseService) IsCourseOwner(ctx context.Context, courseID uuid.UUID, authID string) (bool, error) {
+	if authID == "" {
+		return false, nil
+	}
+	return s.repo.IsCourseOwner(ctx, courseID, authID)
+}
+
+// StatusHistory mengambil riwayat status course, dari yang paling lama
+func (s *CourseService) StatusHistory(ctx context.Context, courseID uuid.UUID) ([]*models.CourseStatusEvent, error) {
+	return s.repo.GetCourseStatusHistory(ctx, courseID)
+}
+
+// Readiness menjalankan checklist aturan sebelum course boleh diajukan ke review
+func (s *CourseService) Readiness(ctx context.Context, courseID uuid.UUID) (*models.ReadinessReport, error) {
+	course, err := s.repo.GetCourseDetails(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return models.CheckReadiness(course, s.readiness), nil
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/observability"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// newTestService merakit CourseService di atas fakeRepository & fakeUnitOfWork
+// dengan StatusPolicy bawaan dan minimal satu chapter
+func newTestService(t *testing.T) (*CourseService, *fakeRepository, *fakeUnitOfWork, *observability.Metrics) {
+	t.Helper()
+	policy, err := models.NewStatusPolicy("")
+	if err != nil {
+		t.Fatalf("NewStatusPolicy: %v", err)
+	}
+	repo := newFakeRepository()
+	uow := &fakeUnitOfWork{repo: repo}
+	metrics := observability.NewMetrics()
+	return NewCourseService(repo, uow, policy, models.ReadinessConfig{MinChapters: 1}, metrics), repo, uow, metrics
+}
+
+// counterValue membaca nilai counter dari registry metrik (0 jika tidak ada)
+func counterValue(t *testing.T, m *observability.Metrics, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+// addCourse menyimpan course langsung ke fakeDB
+func addCourse(repo *fakeRepository, course *models.Course) *models.Course {
+	if course.ID == uuid.Nil {
+		course.ID = uuid.New()
+	}
+	if course.Version == 0 {
+		course.Version = 1
+	}
+	repo.db.courses[course.ID] = course
+	return course
+}
+
+// readyCourse membuat course berstatus 'status' yang lolos cek readiness
+func readyCourse(status models.CourseStatus) *models.Course {
+	return &models.Course{
+		Status:      status,
+		Description: "Belajar Go dari nol",
+		Thumbnail:   "https://cdn.example.com/go.png",
+		Level:       models.LevelBeginner,
+		Price:       150000,
+		Categories:  []models.Category{{ID: uuid.New(), Name: "Backend"}},
+		Chapters: []models.Chapter{{
+			ID:      uuid.New(),
+			Lessons: []models.Lesson{{ID: uuid.New(), PlaybackID: "pb-1", Duration: 300}},
+		}},
+	}
+}
+
+func TestCreateCourse(t *testing.T) {
+	tests := []struct {
+		name         string
+		existingSlug string
+		fail         map[string]error
+		wantErr      error
+		wantSlug     string
+	}{
+		{name: "draft course for new teacher", wantSlug: "belajar-go"},
+		{name: "slug taken", existingSlug: "belajar-go", wantSlug: "belajar-go-2"},
+		{name: "insert fails", fail: map[string]error{"CreateCourse": errors.New("insert failed")}, wantErr: errors.New("insert failed")},
+		{name: "teacher lookup fails", fail: map[string]error{"FindOrCreateTeacherByAuthID": repository.ErrCourseNotFound}, wantErr: repository.ErrCourseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, uow, metrics := newTestService(t)
+			if tt.existingSlug != "" {
+				addCourse(repo, &models.Course{Slug: tt.existingSlug})
+			}
+			repo.fail = tt.fail
+			coursesBefore := len(repo.db.courses)
+
+			course, err := svc.CreateCourse(context.Background(), CreateCourseInput{AuthID: "teacher-1", Title: "Belajar Go"})
+
+			created := counterValue(t, metrics, "course_service_courses_created_total", nil)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				// Rollback: profil teacher dari langkah 1 ikut dibatalkan
+				if len(repo.db.teachers) != 0 || len(repo.db.courses) != coursesBefore {
+					t.Fatalf("rollback left rows: %d teachers, %d courses", len(repo.db.teachers), len(repo.db.courses))
+				}
+				if uow.rollbacks != 1 || len(repo.db.events) != 0 || created != 0 {
+					t.Fatalf("rollbacks = %d, events = %v, created metric = %v", uow.rollbacks, repo.db.events, created)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CreateCourse: %v", err)
+			}
+			if course.Status != models.StatusDraft || course.License != models.LicenseNT || course.Slug != tt.wantSlug {
+				t.Fatalf("course = {Status:%s License:%s Slug:%s}, want DRAFT/NT/%s", course.Status, course.License, course.Slug, tt.wantSlug)
+			}
+			if course.TeacherID != repo.db.teachers["teacher-1"].ID {
+				t.Fatal("course is not linked to the caller's teacher profile")
+			}
+			if uow.commits != 1 || !slices.Equal(repo.db.events, []string{"course.created"}) || created != 1 {
+				t.Fatalf("commits = %d, events = %v, created metric = %v", uow.commits, repo.db.events, created)
+			}
+		})
+	}
+}
+
+func TestUpdateCourse(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int64
+		unknown     bool
+		wantErr     error
+		wantVersion int64
+	}{
+		{name: "current version", version: 3, wantVersion: 4},
+		{name: "stale version", version: 2, wantErr: repository.ErrVersionConflict, wantVersion: 3},
+		{name: "unknown course", unknown: true, version: 1, wantErr: repository.ErrCourseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _, _ := newTestService(t)
+			course := addCourse(repo, &models.Course{Title: "Lama", Version: 3})
+			courseID := course.ID
+			if tt.unknown {
+				courseID = uuid.New()
+			}
+
+			updated, err := svc.UpdateCourse(context.Background(), courseID, repository.UpdateCourseInput{Title: "Baru", Version: tt.version})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			var conflict *VersionConflictError
+			switch {
+			case errors.Is(tt.wantErr, repository.ErrVersionConflict):
+				// Konflik membawa representasi terkini untuk di-merge client
+				if !errors.As(err, &conflict) {
+					t.Fatalf("err = %T, want *VersionConflictError", err)
+				}
+				current, ok := conflict.Current.(*models.Course)
+				if conflict.Version != tt.wantVersion || !ok || current.Title != "Lama" {
+					t.Fatalf("conflict = {Version:%d Current:%+v}, want version %d with the stored course", conflict.Version, conflict.Current, tt.wantVersion)
+				}
+			case tt.wantErr != nil:
+				if errors.As(err, &conflict) {
+					t.Fatal("non-conflict error must not be a VersionConflictError")
+				}
+			default:
+				if updated.Version != tt.wantVersion || updated.Title != "Baru" {
+					t.Fatalf("updated = {Version:%d Title:%s}, want version %d", updated.Version, updated.Title, tt.wantVersion)
+				}
+			}
+		})
+	}
+}
+
+func TestChangeStatus(t *testing.T) {
+	teacher := []models.Role{models.RoleTeacher}
+	curator := []models.Role{models.RoleCurator}
+	teacherCurator := []models.Role{models.RoleTeacher, models.RoleCurator}
+
+	notReady := readyCourse(models.StatusDraft)
+	notReady.Chapters = nil
+
+	notReadyIncomplete := readyCourse(models.StatusIncomplete)
+	notReadyIncomplete.Description = ""
+
+	tests := []struct {
+		name             string
+		course           *models.Course
+		target           models.CourseStatus
+		roles            []models.Role
+		owner            bool
+		wantErr          error
+		wantErrType      any
+		wantStatus       models.CourseStatus
+		wantEvents       []string
+		wantMarked       any // Param 'markedIncomplete' pada COURSE_NOT_READY
+		wantTransitionTo map[string]float64
+	}{
+		{
+			name: "submit ready course", course: readyCourse(models.StatusDraft), target: models.StatusPending, roles: teacher, owner: true,
+			wantStatus: models.StatusPending, wantEvents: []string{"course.status.PENDING_REVIEW"},
+			wantTransitionTo: map[string]float64{"PENDING_REVIEW": 1},
+		},
+		{
+			name: "curator approves", course: readyCourse(models.StatusPending), target: models.StatusApproved, roles: curator,
+			wantStatus: models.StatusApproved, wantEvents: []string{"course.status.APPROVED"},
+			wantTransitionTo: map[string]float64{"APPROVED": 1},
+		},
+		{
+			name: "unknown status", course: readyCourse(models.StatusDraft), target: "PUBLISH", roles: teacher, owner: true,
+			wantErr: ErrInvalidStatus, wantStatus: models.StatusDraft,
+		},
+		{
+			name: "transition not in table", course: readyCourse(models.StatusDraft), target: models.StatusPublished, roles: teacher, owner: true,
+			wantErrType: &models.StatusTransitionError{}, wantStatus: models.StatusDraft,
+		},
+		{
+			name: "role may not transition", course: readyCourse(models.StatusDraft), target: models.StatusPending, roles: curator,
+			wantErrType: &models.StatusRoleError{}, wantStatus: models.StatusDraft,
+		},
+		{
+			// TEACHER hanya berlaku pada course milik sendiri
+			name: "teacher-curator withdraws another teacher's submission", course: readyCourse(models.StatusPending), target: models.StatusDraft, roles: teacherCurator,
+			wantErrType: &models.StatusRoleError{}, wantStatus: models.StatusPending,
+		},
+		{
+			name: "teacher-curator archives another teacher's course", course: readyCourse(models.StatusDraft), target: models.StatusArchived, roles: teacherCurator,
+			wantErrType: &models.StatusRoleError{}, wantStatus: models.StatusDraft,
+		},
+		{
+			// CURATOR tidak berlaku pada course milik sendiri
+			name: "teacher-curator approves own submission", course: readyCourse(models.StatusPending), target: models.StatusApproved, roles: teacherCurator, owner: true,
+			wantErrType: &models.StatusRoleError{}, wantStatus: models.StatusPending,
+		},
+		{
+			name: "teacher-curator reviews another teacher's course", course: readyCourse(models.StatusPending), target: models.StatusRejected, roles: teacherCurator,
+			wantStatus: models.StatusRejected, wantEvents: []string{"course.status.REJECTED"},
+		},
+		{
+			// PENDING di-rollback, lalu course ditandai INCOMPLETE (di luar transaksi)
+			name: "submit course that is not ready", course: notReady, target: models.StatusPending, roles: teacher, owner: true,
+			wantErr: repository.ErrCourseNotReady, wantStatus: models.StatusIncomplete, wantMarked: true,
+			wantEvents:       []string{"course.status.INCOMPLETE"},
+			wantTransitionTo: map[string]float64{"PENDING_REVIEW": 0, "INCOMPLETE": 1},
+		},
+		{
+			name: "resubmit incomplete course that is still not ready", course: notReadyIncomplete, target: models.StatusPending, roles: teacher, owner: true,
+			wantErr: repository.ErrCourseNotReady, wantStatus: models.StatusIncomplete, wantMarked: false,
+			wantTransitionTo: map[string]float64{"PENDING_REVIEW": 0, "INCOMPLETE": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _, metrics := newTestService(t)
+			course := addCourse(repo, tt.course)
+			from := course.Status
+
+			err := svc.ChangeStatus(context.Background(), ChangeStatusInput{
+				CourseID:    course.ID,
+				Status:      tt.target,
+				ActorAuthID: "actor-1",
+				Roles:       tt.roles,
+				IsOwner:     tt.owner,
+			})
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantErrType != nil:
+				if err == nil || !sameErrorType(err, tt.wantErrType) {
+					t.Fatalf("err = %T (%v), want %T", err, err, tt.wantErrType)
+				}
+			case err != nil:
+				t.Fatalf("ChangeStatus: %v", err)
+			}
+
+			if tt.wantMarked != nil {
+				var domainErr *apperr.Error
+				if !errors.As(err, &domainErr) || domainErr.Params["markedIncomplete"] != tt.wantMarked || domainErr.Params["readiness"] == nil {
+					t.Fatalf("COURSE_NOT_READY params = %v, want markedIncomplete=%v with readiness", domainErr.Params, tt.wantMarked)
+				}
+			}
+			if got := repo.db.courses[course.ID].Status; got != tt.wantStatus {
+				t.Fatalf("stored status = %s, want %s", got, tt.wantStatus)
+			}
+			// Event hanya terkirim untuk transaksi yang di-commit
+			if !slices.Equal(repo.db.events, tt.wantEvents) {
+				t.Fatalf("events = %v, want %v", repo.db.events, tt.wantEvents)
+			}
+			for to, want := range tt.wantTransitionTo {
+				got := counterValue(t, metrics, "course_service_course_status_transitions_total", map[string]string{"from": string(from), "to": to})
+				if got != want {
+					t.Fatalf("transitions{%s->%s} = %v, want %v", from, to, got, want)
+				}
+			}
+		})
+	}
+}
+
+// sameErrorType: err (atau penyebabnya) bertipe sama dengan 'want'
+func sameErrorType(err error, want any) bool {
+	switch want.(type) {
+	case *models.StatusTransitionError:
+		var target *models.StatusTransitionError
+		return errors.As(err, &target)
+	case *models.StatusRoleError:
+		var target *models.StatusRoleError
+		return errors.As(err, &target)
+	}
+	return false
+}
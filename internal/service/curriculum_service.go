@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+	"github.com/wtppaul/course-service/internal/utils"
+)
+
+// --- Chapter ---
+
+type CreateChapterInput struct {
+	CourseID uuid.UUID
+	Title    string
+	Order    int
+}
+
+// CreateChapter membuat chapter dengan slug "chapter-<judul>-<acak>"
+// (cukup unik tanpa cek DB)
+func (s *CourseService) CreateChapter(ctx context.Context, input CreateChapterInput) (*models.Chapter, error) {
+	chapter := &models.Chapter{
+		CourseID: input.CourseID,
+		Title:    input.Title,
+		Order:    input.Order,
+		Slug:     chapterSlug(input.Title),
+	}
+	if err := s.repo.CreateChapter(ctx, chapter); err != nil {
+		return nil, err
+	}
+	return chapter, nil
+}
+
+// UpdateChapterInput: nil / "" = field tidak diubah.
+// Version 0 = tanpa pengecekan versi.
+type UpdateChapterInput struct {
+	CourseID  uuid.UUID
+	ChapterID uuid.UUID
+	Title     string
+	Order     *int
+	Version   int64
+}
+
+// UpdateChapter mengubah chapter milik CourseID (compare-and-swap pada versi)
+func (s *CourseService) UpdateChapter(ctx context.Context, input UpdateChapterInput) (*models.Chapter, error) {
+	// 1. Chapter harus milik course yang diminta
+	chapter, err := s.repo.GetChapterByID(ctx, input.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+	if chapter.CourseID != input.CourseID {
+		return nil, repository.ErrChapterNotFound
+	}
+
+	// 2. Terapkan perubahan
+	if input.Title != "" {
+		chapter.Title = input.Title
+	}
+	if input.Order != nil {
+		chapter.Order = *input.Order
+	}
+	if input.Version != 0 && input.Version != chapter.Version {
+		return nil, &VersionConflictError{Version: chapter.Version, Current: chapter}
+	}
+	chapter.Version = input.Version
+
+	// 3. Simpan
+	updated, err := s.repo.UpdateChapter(ctx, chapter)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		if current, err := s.repo.GetChapterByID(ctx, input.ChapterID); err == nil {
+			return nil, &VersionConflictError{Version: current.Version, Current: current}
+		}
+	}
+	return updated, err
+}
+
+// ReorderChapters menyimpan urutan baru chapter milik course (satu transaksi).
+// 'version' adalah versi course yang dilihat editor; mengembalikan versi baru.
+func (s *CourseService) ReorderChapters(ctx context.Context, courseID uuid.UUID, version int64, updates []repository.ChapterReorderInput) (int64, error) {
+	newVersion, err := s.repo.ReorderChapters(ctx, courseID, version, updates)
+	if err != nil {
+		return 0, s.curriculumConflict(ctx, courseID, err)
+	}
+	return newVersion, nil
+}
+
+// DeleteChapter menghapus chapter beserta lesson-nya
+func (s *CourseService) DeleteChapter(ctx context.Context, courseID, chapterID uuid.UUID) error {
+	return s.repo.DeleteChapter(ctx, courseID, chapterID)
+}
+
+// --- Lesson ---
+
+type CreateLessonInput struct {
+	ChapterID  uuid.UUID
+	Title      string
+	Order      int
+	PlaybackID string // ID video (dari Upload-service), boleh kosong
+}
+
+func (s *CourseService) CreateLesson(ctx context.Context, input CreateLessonInput) (*models.Lesson, error) {
+	lesson := &models.Lesson{
+		Title:      input.Title,
+		Order:      input.Order,
+		ChapterID:  input.ChapterID,
+		PlaybackID: input.PlaybackID,
+	}
+	if err := s.repo.CreateLesson(ctx, lesson); err != nil {
+		return nil, err
+	}
+	return lesson, nil
+}
+
+// UpdateLessonInput: nil = field tidak diubah. Version 0 = tanpa pengecekan.
+// ('duration' di-update oleh upload-pipeline, bukan lewat sini)
+type UpdateLessonInput struct {
+	LessonID   uuid.UUID
+	Title      *string
+	Order      *int
+	PlaybackID *string
+	IsPreview  *bool
+	Version    int64
+}
+
+// UpdateLesson mengubah lesson (compare-and-swap pada versi)
+func (s *CourseService) UpdateLesson(ctx context.Context, input UpdateLessonInput) (*models.Lesson, error) {
+	lesson, err := s.repo.GetLessonByID(ctx, input.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil {
+		lesson.Title = *input.Title
+	}
+	if input.Order != nil {
+		lesson.Order = *input.Order
+	}
+	if input.PlaybackID != nil {
+		lesson.PlaybackID = *input.PlaybackID
+	}
+	if input.IsPreview != nil {
+		lesson.IsPreview = *input.IsPreview
+	}
+	if input.Version != 0 && input.Version != lesson.Version {
+		return nil, &VersionConflictError{Version: lesson.Version, Current: lesson}
+	}
+	lesson.Version = input.Version
+
+	updated, err := s.repo.UpdateLesson(ctx, lesson)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		if current, err := s.repo.GetLessonByID(ctx, input.LessonID); err == nil {
+			return nil, &VersionConflictError{Version: current.Version, Current: current}
+		}
+	}
+	return updated, err
+}
+
+// DeleteLesson menghapus lesson dan merapikan urutan lesson di chapter-nya
+func (s *CourseService) DeleteLesson(ctx context.Context, lessonID uuid.UUID) error {
+	return s.repo.DeleteLesson(ctx, lessonID)
+}
+
+// ReorderLessons menyimpan urutan baru lesson dalam satu chapter
+// ('version' = versi course, seperti ReorderChapters)
+func (s *CourseService) ReorderLessons(ctx context.Context, chapterID uuid.UUID, version int64, updates []repository.LessonReorderInput) (int64, error) {
+	newVersion, err := s.repo.ReorderLessons(ctx, chapterID, version, updates)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		if chapter, chapterErr := s.repo.GetChapterByID(ctx, chapterID); chapterErr == nil {
+			return 0, s.curriculumConflict(ctx, chapter.CourseID, err)
+		}
+	}
+	return newVersion, err
+}
+
+// MoveLesson memindahkan lesson ke chapter lain di course yang sama.
+// Cek chapter tujuan & pemindahan berjalan dalam satu transaksi.
+// 'version' adalah versi course yang dilihat editor; mengembalikan lesson
+// hasil pindah beserta versi course yang baru.
+func (s *CourseService) MoveLesson(ctx context.Context, lessonID, targetChapterID uuid.UUID, position int, version int64) (*models.Lesson, int64, error) {
+	var (
+		moved      *models.Lesson
+		courseID   uuid.UUID
+		newVersion int64
+	)
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		// 1. Lesson & chapter tujuan harus berada di course yang sama
+		lesson, err := repo.GetLessonByID(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+		courseID = lesson.CourseID
+		target, err := repo.GetChapterByID(ctx, targetChapterID)
+		if err != nil {
+			return err
+		}
+		if target.CourseID != lesson.CourseID {
+			return repository.ErrLessonMoveCourse
+		}
+
+		// 2. Pindahkan (kedua chapter dinomori ulang)
+		moved, newVersion, err = repo.MoveLesson(ctx, lessonID, targetChapterID, position, version)
+		return err
+	})
+	if err != nil {
+		return nil, 0, s.curriculumConflict(ctx, courseID, err)
+	}
+	return moved, newVersion, nil
+}
+
+// --- Curriculum ---
+
+// SaveCurriculum menyimpan seluruh tree chapter/lesson (autosave editor)
+// dalam satu transaksi. Chapter baru mendapat slug seperti CreateChapter.
+// 'version' adalah versi course yang dilihat editor; tab yang usang
+// mendapat *VersionConflictError berisi course (dan tree) terkini.
+func (s *CourseService) SaveCurriculum(ctx context.Context, courseID uuid.UUID, version int64, chapters []repository.CurriculumChapterInput) (*repository.CurriculumSaveResult, error) {
+	for i := range chapters {
+		if chapters[i].ID == nil {
+			chapters[i].Slug = chapterSlug(chapters[i].Title)
+		}
+	}
+	result, err := s.repo.SaveCurriculum(ctx, courseID, version, chapters)
+	if err != nil {
+		return nil, s.curriculumConflict(ctx, courseID, err)
+	}
+	return result, nil
+}
+
+// curriculumConflict: versi course usang -> *VersionConflictError berisi
+// course terkini (termasuk tree chapter/lesson) agar editor bisa merge lalu
+// mencoba lagi; error lain diteruskan apa adanya
+func (s *CourseService) curriculumConflict(ctx context.Context, courseID uuid.UUID, err error) error {
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		return err
+	}
+	if current, loadErr := s.repo.GetCourseDetails(ctx, courseID); loadErr == nil {
+		return &VersionConflictError{Version: current.Version, Current: current}
+	}
+	return err
+}
+
+func chapterSlug(title string) string {
+	return fmt.Sprintf("chapter-%s-%s", utils.CreateSlug(title), utils.RandomString(6))
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+func TestMoveLesson(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	course := addCourse(repo, &models.Course{})
+	otherCourse := addCourse(repo, &models.Course{})
+
+	source := &models.Chapter{ID: uuid.New(), CourseID: course.ID}
+	target := &models.Chapter{ID: uuid.New(), CourseID: course.ID}
+	foreign := &models.Chapter{ID: uuid.New(), CourseID: otherCourse.ID}
+	for _, chapter := range []*models.Chapter{source, target, foreign} {
+		repo.db.chapters[chapter.ID] = chapter
+	}
+
+	tests := []struct {
+		name          string
+		targetChapter uuid.UUID
+		stale         bool  // Kirim versi course sebelum versi terkini
+		fail          error // Error dari repo.MoveLesson
+		wantErr       error
+		wantChapter   uuid.UUID
+		wantEvents    []string
+	}{
+		{name: "same course", targetChapter: target.ID, wantChapter: target.ID, wantEvents: []string{"lesson.moved"}},
+		{name: "stale course version", targetChapter: target.ID, stale: true, wantErr: repository.ErrVersionConflict, wantChapter: source.ID},
+		{name: "other course", targetChapter: foreign.ID, wantErr: repository.ErrLessonMoveCourse, wantChapter: source.ID},
+		{name: "unknown chapter", targetChapter: uuid.New(), wantErr: repository.ErrChapterNotFound, wantChapter: source.ID},
+		{name: "move fails", targetChapter: target.ID, fail: repository.ErrLessonNotInChapter, wantErr: repository.ErrLessonNotInChapter, wantChapter: source.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lesson := &models.Lesson{ID: uuid.New(), ChapterID: source.ID, Order: 1}
+			repo.db.lessons[lesson.ID] = lesson
+			repo.db.events = nil
+			repo.fail = map[string]error{"MoveLesson": tt.fail}
+
+			version := course.Version
+			if tt.stale {
+				version--
+			}
+
+			moved, newVersion, err := svc.MoveLesson(context.Background(), lesson.ID, tt.targetChapter, 1, version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.stale {
+				assertCurriculumConflict(t, err, course.Version)
+			}
+			if err == nil && (moved.ChapterID != tt.wantChapter || moved.CourseID != course.ID) {
+				t.Fatalf("moved = {ChapterID:%s CourseID:%s}, want chapter %s", moved.ChapterID, moved.CourseID, tt.wantChapter)
+			}
+			if err == nil && (newVersion != version+1 || course.Version != newVersion) {
+				t.Fatalf("version = %d (stored %d), want %d", newVersion, course.Version, version+1)
+			}
+			if got := repo.db.lessons[lesson.ID].ChapterID; got != tt.wantChapter {
+				t.Fatalf("stored chapter = %s, want %s", got, tt.wantChapter)
+			}
+			if !slices.Equal(repo.db.events, tt.wantEvents) {
+				t.Fatalf("events = %v, want %v", repo.db.events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestMoveLessonRunsInOneTransaction(t *testing.T) {
+	svc, repo, uow, _ := newTestService(t)
+	course := addCourse(repo, &models.Course{})
+	chapter := &models.Chapter{ID: uuid.New(), CourseID: course.ID}
+	repo.db.chapters[chapter.ID] = chapter
+	lesson := &models.Lesson{ID: uuid.New(), ChapterID: chapter.ID}
+	repo.db.lessons[lesson.ID] = lesson
+
+	if _, _, err := svc.MoveLesson(context.Background(), lesson.ID, chapter.ID, 1, course.Version); err != nil {
+		t.Fatalf("MoveLesson: %v", err)
+	}
+	if _, _, err := svc.MoveLesson(context.Background(), lesson.ID, uuid.New(), 1, course.Version); err == nil {
+		t.Fatal("move to unknown chapter succeeded")
+	}
+	if uow.commits != 1 || uow.rollbacks != 1 {
+		t.Fatalf("commits = %d, rollbacks = %d, want 1 and 1", uow.commits, uow.rollbacks)
+	}
+}
+
+func TestSaveCurriculum(t *testing.T) {
+	existingID := uuid.New()
+	chapterSlugPattern := regexp.MustCompile(`^chapter-pengenalan-go-[a-z0-9]{6}$`)
+
+	tests := []struct {
+		name    string
+		version int64 // Versi course tersimpan = 3
+		fail    error
+		wantErr error
+	}{
+		{name: "saved", version: 3},
+		{name: "stale version", version: 2, wantErr: repository.ErrVersionConflict},
+		{name: "invalid tree", version: 3, fail: repository.ErrInvalidCurriculum, wantErr: repository.ErrInvalidCurriculum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _, _ := newTestService(t)
+			course := addCourse(repo, &models.Course{Version: 3})
+			repo.fail = map[string]error{"SaveCurriculum": tt.fail}
+
+			chapters := []repository.CurriculumChapterInput{
+				{ID: &existingID, Title: "Chapter lama"},
+				{ClientID: "tmp-1", Title: "Pengenalan Go", Lessons: []repository.CurriculumLessonInput{{ClientID: "tmp-2", Title: "Instalasi"}}},
+			}
+			result, err := svc.SaveCurriculum(context.Background(), course.ID, tt.version, chapters)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(tt.wantErr, repository.ErrVersionConflict) {
+				// Tab usang tidak menimpa apa pun dan menerima tree terkini
+				assertCurriculumConflict(t, err, 3)
+				if repo.db.curriculum != nil {
+					t.Fatal("stale save reached the curriculum")
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if result.Version != 4 || course.Version != 4 {
+				t.Fatalf("version = %d (stored %d), want 4", result.Version, course.Version)
+			}
+
+			saved := repo.db.curriculum
+			if len(saved) != 2 {
+				t.Fatalf("saved %d chapters, want 2", len(saved))
+			}
+			// Slug hanya untuk chapter baru; chapter lama tetap memakai slug tersimpan
+			if saved[0].Slug != "" {
+				t.Fatalf("existing chapter slug = %q, want untouched", saved[0].Slug)
+			}
+			if !chapterSlugPattern.MatchString(saved[1].Slug) {
+				t.Fatalf("new chapter slug = %q, want chapter-pengenalan-go-<6 chars>", saved[1].Slug)
+			}
+		})
+	}
+}
+
+// assertCurriculumConflict: err harus *VersionConflictError berisi course
+// terkini dengan versi 'want'
+func assertCurriculumConflict(t *testing.T, err error, want int64) {
+	t.Helper()
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %T, want *VersionConflictError", err)
+	}
+	current, ok := conflict.Current.(*models.Course)
+	if !ok || conflict.Version != want || current.Version != want {
+		t.Fatalf("conflict = {Version:%d Current:%T}, want current course at version %d", conflict.Version, conflict.Current, want)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// GrantEnrollment memberi akses course ke student (aman dipanggil ulang:
+// enrollment aktif yang sudah ada diperpanjang & memakai source/reference
+// terbaru). created = true jika enrollment baru dibuat. Source FREE hanya
+// untuk course gratis.
+func (s *CourseService) GrantEnrollment(ctx context.Context, input repository.GrantEnrollmentInput) (enrollment *models.Enrollment, created bool, err error) {
+	// 1. Validasi input
+	input.Source = models.EnrollmentSource(strings.ToUpper(string(input.Source)))
+	if !input.Source.IsValid() {
+		return nil, false, ErrInvalidEnrollmentSource.With("source", input.Source)
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, false, ErrExpiresAtNotFuture
+	}
+
+	// 2. Source FREE hanya untuk course gratis
+	if input.Source == models.EnrollmentFree {
+		course, err := s.repo.GetCourseByID(ctx, input.CourseID)
+		if err != nil {
+			return nil, false, err
+		}
+		if !course.IsFree {
+			return nil, false, repository.ErrCourseNotFree
+		}
+	}
+
+	// 3. Simpan
+	return s.repo.GrantEnrollment(ctx, input)
+}
+
+// RevokeEnrollment mencabut enrollment aktif (misal refund). Riwayat tetap disimpan.
+func (s *CourseService) RevokeEnrollment(ctx context.Context, authID string, courseID uuid.UUID, reason string) (*models.Enrollment, error) {
+	return s.repo.RevokeEnrollment(ctx, authID, courseID, reason)
+}
+
+// AccessInput: apakah 'AuthID' boleh menonton lesson 'LessonID' di course?
+// LessonID nil = akses level course (lesson non-preview).
+type AccessInput struct {
+	CourseID uuid.UUID
+	AuthID   string
+	LessonID *uuid.UUID
+}
+
+// CourseAccess adalah hasil keputusan akses beserta enrollment (jika ada)
+type CourseAccess struct {
+	models.LessonAccess
+	CourseID   uuid.UUID
+	AuthID     string
+	LessonID   *uuid.UUID
+	Enrollment *models.Enrollment
+}
+
+// CheckAccess memutuskan akses berdasarkan kepemilikan course, enrollment
+// aktif, status course dan flag preview lesson (lihat ResolveLessonAccess)
+func (s *CourseService) CheckAccess(ctx context.Context, input AccessInput) (*CourseAccess, error) {
+	if input.AuthID == "" {
+		return nil, ErrAuthIDRequired
+	}
+
+	// 1. Course & lesson (lesson harus milik course ini)
+	course, err := s.repo.GetCourseByID(ctx, input.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	var lesson *models.Lesson
+	if input.LessonID != nil {
+		lesson, err = s.repo.GetLessonByID(ctx, *input.LessonID)
+		if err != nil {
+			return nil, err
+		}
+		if lesson.CourseID != input.CourseID {
+			return nil, repository.ErrLessonNotFound
+		}
+	}
+
+	// 2. Kepemilikan & enrollment
+	isOwner, err := s.repo.IsCourseOwner(ctx, input.CourseID, input.AuthID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.repo.GetEnrollment(ctx, input.AuthID, input.CourseID)
+	if err != nil && !errors.Is(err, repository.ErrEnrollmentNotFound) {
+		return nil, err
+	}
+
+	// 3. Putuskan
+	return &CourseAccess{
+		LessonAccess: models.ResolveLessonAccess(course, lesson, isOwner, enrollment, time.Now()),
+		CourseID:     input.CourseID,
+		AuthID:       input.AuthID,
+		LessonID:     input.LessonID,
+		Enrollment:   enrollment,
+	}, nil
+}
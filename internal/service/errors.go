@@ -0,0 +1,32 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/wtppaul/course-service/internal/apperr"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// Error validasi input service (error domain lain ada di package repository)
+var (
+	ErrInvalidStatus           = apperr.NewError(apperr.KindInvalidInput, "INVALID_STATUS", "invalid course status")
+	ErrAuthIDRequired          = apperr.NewError(apperr.KindInvalidInput, "AUTH_ID_REQUIRED", "authId is required")
+	ErrInvalidEnrollmentSource = apperr.NewError(apperr.KindInvalidInput, "INVALID_ENROLLMENT_SOURCE", "invalid enrollment source")
+	ErrExpiresAtNotFuture      = apperr.NewError(apperr.KindInvalidInput, "EXPIRES_AT_NOT_FUTURE", "expiresAt must be in the future")
+)
+
+// VersionConflictError dikembalikan update dengan versi usang. Current
+// berisi representasi terkini agar pemanggil bisa menggabungkan perubahan.
+// errors.Is(err, repository.ErrVersionConflict) tetap true.
+type VersionConflictError struct {
+	Version int64
+	Current interface{}
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s (current version %d)", repository.ErrVersionConflict.Message, e.Version)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return repository.ErrVersionConflict
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// fakeDB adalah "tabel" di memori milik fakeRepository
+type fakeDB struct {
+	teachers      map[string]*models.Teacher // authID -> teacher
+	courses       map[uuid.UUID]*models.Course
+	chapters      map[uuid.UUID]*models.Chapter
+	lessons       map[uuid.UUID]*models.Lesson
+	statusChanges []repository.CourseStatusChange
+	curriculum    []repository.CurriculumChapterInput // Input SaveCurriculum terakhir
+	events        []string                            // Event yang terkirim (setelah commit)
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		teachers: map[string]*models.Teacher{},
+		courses:  map[uuid.UUID]*models.Course{},
+		chapters: map[uuid.UUID]*models.Chapter{},
+		lessons:  map[uuid.UUID]*models.Lesson{},
+	}
+}
+
+// clone menyalin semua baris (snapshot untuk rollback)
+func (db *fakeDB) clone() *fakeDB {
+	c := newFakeDB()
+	for k, v := range db.teachers {
+		row := *v
+		c.teachers[k] = &row
+	}
+	for k, v := range db.courses {
+		row := *v
+		c.courses[k] = &row
+	}
+	for k, v := range db.chapters {
+		row := *v
+		c.chapters[k] = &row
+	}
+	for k, v := range db.lessons {
+		row := *v
+		c.lessons[k] = &row
+	}
+	c.statusChanges = append(c.statusChanges, db.statusChanges...)
+	c.curriculum = append(c.curriculum, db.curriculum...)
+	c.events = append(c.events, db.events...)
+	return c
+}
+
+// fakeRepository mengimplementasikan method ICourseRepository yang dipakai
+// test service; method lain panic lewat interface nil yang di-embed
+type fakeRepository struct {
+	repository.ICourseRepository
+	db   *fakeDB
+	fail map[string]error // Nama method -> error yang dikembalikan
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{db: newFakeDB(), fail: map[string]error{}}
+}
+
+// publish mengirim event seperti outbox/invalidasi cache repository asli:
+// di dalam unit of work ditunda sampai commit, di luar langsung terkirim
+func (r *fakeRepository) publish(ctx context.Context, event string) {
+	if tx, ok := ctx.Value(fakeTxKey{}).(*fakeTx); ok {
+		tx.afterCommit = append(tx.afterCommit, func() { r.db.events = append(r.db.events, event) })
+		return
+	}
+	r.db.events = append(r.db.events, event)
+}
+
+func (r *fakeRepository) FindOrCreateTeacherByAuthID(ctx context.Context, authID string) (*models.Teacher, error) {
+	if err := r.fail["FindOrCreateTeacherByAuthID"]; err != nil {
+		return nil, err
+	}
+	if teacher, ok := r.db.teachers[authID]; ok {
+		return teacher, nil
+	}
+	teacher := &models.Teacher{ID: uuid.New(), AuthID: authID}
+	r.db.teachers[authID] = teacher
+	return teacher, nil
+}
+
+func (r *fakeRepository) IsSlugInUse(ctx context.Context, slug string) (bool, error) {
+	for _, course := range r.db.courses {
+		if course.Slug == slug {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRepository) CreateCourse(ctx context.Context, course *models.Course) error {
+	if err := r.fail["CreateCourse"]; err != nil {
+		return err
+	}
+	course.ID = uuid.New()
+	course.Version = 1
+	row := *course
+	r.db.courses[course.ID] = &row
+	r.publish(ctx, "course.created")
+	return nil
+}
+
+func (r *fakeRepository) UpdateCourse(ctx context.Context, courseID uuid.UUID, input repository.UpdateCourseInput) (*models.Course, error) {
+	course, ok := r.db.courses[courseID]
+	if !ok {
+		return nil, repository.ErrCourseNotFound
+	}
+	if input.Version != course.Version {
+		return nil, repository.ErrVersionConflict
+	}
+	course.Title = input.Title
+	course.Version++
+	r.publish(ctx, "course.updated")
+	row := *course
+	return &row, nil
+}
+
+func (r *fakeRepository) GetCourseDetails(ctx context.Context, courseID uuid.UUID) (*models.Course, error) {
+	course, ok := r.db.courses[courseID]
+	if !ok {
+		return nil, repository.ErrCourseNotFound
+	}
+	row := *course
+	return &row, nil
+}
+
+func (r *fakeRepository) UpdateCourseStatus(ctx context.Context, courseID uuid.UUID, change repository.CourseStatusChange, check repository.StatusCheckFunc) error {
+	course, ok := r.db.courses[courseID]
+	if !ok {
+		return repository.ErrCourseNotFound
+	}
+	if err := check(course.Status); err != nil {
+		return err
+	}
+	course.Status = change.Status
+	r.db.statusChanges = append(r.db.statusChanges, change)
+	r.publish(ctx, "course.status."+string(change.Status))
+	return nil
+}
+
+func (r *fakeRepository) GetChapterByID(ctx context.Context, chapterID uuid.UUID) (*models.Chapter, error) {
+	chapter, ok := r.db.chapters[chapterID]
+	if !ok {
+		return nil, repository.ErrChapterNotFound
+	}
+	row := *chapter
+	return &row, nil
+}
+
+func (r *fakeRepository) GetLessonByID(ctx context.Context, lessonID uuid.UUID) (*models.Lesson, error) {
+	lesson, ok := r.db.lessons[lessonID]
+	if !ok {
+		return nil, repository.ErrLessonNotFound
+	}
+	row := *lesson
+	row.CourseID = r.db.chapters[lesson.ChapterID].CourseID // Seperti JOIN di repository
+	return &row, nil
+}
+
+// bumpCurriculum meniru lockCurriculum + bumpCourseVersion: versi course
+// harus sama dengan 'version', lalu dinaikkan
+func (r *fakeRepository) bumpCurriculum(courseID uuid.UUID, version int64) (int64, error) {
+	course, ok := r.db.courses[courseID]
+	if !ok {
+		return 0, repository.ErrCourseNotFound
+	}
+	if version != course.Version {
+		return 0, repository.ErrVersionConflict
+	}
+	course.Version++
+	return course.Version, nil
+}
+
+func (r *fakeRepository) MoveLesson(ctx context.Context, lessonID, targetChapterID uuid.UUID, position int, version int64) (*models.Lesson, int64, error) {
+	if err := r.fail["MoveLesson"]; err != nil {
+		return nil, 0, err
+	}
+	lesson := r.db.lessons[lessonID]
+	newVersion, err := r.bumpCurriculum(r.db.chapters[lesson.ChapterID].CourseID, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	lesson.ChapterID = targetChapterID
+	lesson.Order = position
+	r.publish(ctx, "lesson.moved")
+	moved, err := r.GetLessonByID(ctx, lessonID)
+	return moved, newVersion, err
+}
+
+func (r *fakeRepository) SaveCurriculum(ctx context.Context, courseID uuid.UUID, version int64, chapters []repository.CurriculumChapterInput) (*repository.CurriculumSaveResult, error) {
+	if err := r.fail["SaveCurriculum"]; err != nil {
+		return nil, err
+	}
+	newVersion, err := r.bumpCurriculum(courseID, version)
+	if err != nil {
+		return nil, err
+	}
+	r.db.curriculum = append([]repository.CurriculumChapterInput(nil), chapters...)
+	return &repository.CurriculumSaveResult{AssignedIDs: map[string]uuid.UUID{}, Version: newVersion}, nil
+}
+
+// fakeUnitOfWork mensimulasikan transaksi: snapshot fakeDB sebelum fn,
+// dikembalikan jika fn gagal (rollback); hook afterCommit hanya dijalankan
+// setelah commit
+type fakeUnitOfWork struct {
+	repo      *fakeRepository
+	commits   int
+	rollbacks int
+}
+
+type fakeTxKey struct{}
+
+type fakeTx struct {
+	afterCommit []func()
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repo repository.ICourseRepository) error) error {
+	// Di dalam transaksi: pakai transaksi yang sama
+	if _, ok := ctx.Value(fakeTxKey{}).(*fakeTx); ok {
+		return fn(ctx, u.repo)
+	}
+
+	snapshot := u.repo.db.clone()
+	tx := &fakeTx{}
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, tx), u.repo); err != nil {
+		u.repo.db = snapshot
+		u.rollbacks++
+		return err
+	}
+	u.commits++
+	for _, hook := range tx.afterCommit {
+		hook()
+	}
+	return nil
+}
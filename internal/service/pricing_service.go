@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+)
+
+// PriceQuote adalah quote harga resmi: Payment-service memakai
+// FinalPrice apa adanya
+type PriceQuote struct {
+	CourseID       uuid.UUID      `json:"courseId"`
+	Price          float64        `json:"price"`
+	IsFree         bool           `json:"isFree"`
+	ActiveSales    []*models.Sale `json:"activeSales"`
+	BestSale       *models.Sale   `json:"bestSale"`
+	DiscountAmount float64        `json:"discountAmount"`
+	FinalPrice     float64        `json:"finalPrice"`
+}
+
+// Quote menghitung harga course setelah sale terbaik (harga akhir paling
+// rendah). Course gratis tidak memakai sale sama sekali.
+func (s *CourseService) Quote(ctx context.Context, courseID uuid.UUID) (*PriceQuote, error) {
+	course, err := s.repo.GetCourseByID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := s.repo.GetActiveSalesForCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &PriceQuote{
+		CourseID:    course.ID,
+		Price:       course.Price,
+		IsFree:      course.IsFree,
+		ActiveSales: sales,
+	}
+	if course.IsFree {
+		// Gratis bukan hasil diskon: FinalPrice & DiscountAmount tetap 0
+		return quote, nil
+	}
+	quote.BestSale, quote.FinalPrice = models.BestSale(course.Price, sales)
+	quote.DiscountAmount = math.Round((course.Price-quote.FinalPrice)*100) / 100
+	return quote, nil
+}
+
+// CouponInput: kode kupon untuk satu course. AuthID hanya dipakai saat redeem.
+type CouponInput struct {
+	Code     string
+	CourseID uuid.UUID
+	AuthID   string
+}
+
+// CouponQuote adalah hasil validasi/redeem kupon. Kupon diterapkan
+// setelah sale terbaik (Price = harga setelah sale).
+type CouponQuote struct {
+	Coupon       *models.Coupon
+	RedemptionID uuid.UUID // Hanya diisi oleh RedeemCoupon
+	Price        float64
+	FinalPrice   float64
+}
+
+// ValidateCoupon hanya mengecek kupon (tidak memakai kuota)
+func (s *CourseService) ValidateCoupon(ctx context.Context, input CouponInput) (*CouponQuote, error) {
+	course, err := s.repo.GetCourseByID(ctx, input.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	coupon, err := s.repo.FindValidCoupon(ctx, input.Code, input.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := s.salePrice(ctx, course)
+	if err != nil {
+		return nil, err
+	}
+	return &CouponQuote{Coupon: coupon, Price: price, FinalPrice: coupon.FinalPrice(price)}, nil
+}
+
+// RedeemCoupon memakai satu kuota kupon secara atomik (dipanggil
+// Payment-service saat checkout)
+func (s *CourseService) RedeemCoupon(ctx context.Context, input CouponInput) (*CouponQuote, error) {
+	course, err := s.repo.GetCourseByID(ctx, input.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := s.salePrice(ctx, course)
+	if err != nil {
+		return nil, err
+	}
+	coupon, redemption, err := s.repo.RedeemCoupon(ctx, input.Code, input.CourseID, input.AuthID)
+	if err != nil {
+		return nil, err
+	}
+	return &CouponQuote{
+		Coupon:       coupon,
+		RedemptionID: redemption.ID,
+		Price:        price,
+		FinalPrice:   coupon.FinalPrice(price),
+	}, nil
+}
+
+// ReleaseCoupon mengembalikan kuota kupon (misal pembayaran gagal)
+func (s *CourseService) ReleaseCoupon(ctx context.Context, redemptionID uuid.UUID) error {
+	return s.repo.ReleaseCoupon(ctx, redemptionID)
+}
+
+// salePrice menghitung harga course setelah sale terbaik (sebelum kupon)
+func (s *CourseService) salePrice(ctx context.Context, course *models.Course) (float64, error) {
+	if course.IsFree {
+		return 0, nil
+	}
+	sales, err := s.repo.GetActiveSalesForCourse(ctx, course.ID)
+	if err != nil {
+		return 0, err
+	}
+	_, price := models.BestSale(course.Price, sales)
+	return price, nil
+}
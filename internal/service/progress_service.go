@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+)
+
+// SaveProgress menyimpan posisi terakhir beberapa lesson milik 'authID'
+// (batch dari player). Aturan merge ada di repository.UpsertLessonProgress.
+func (s *CourseService) SaveProgress(ctx context.Context, authID string, items []repository.LessonProgressInput) ([]*models.LessonProgress, error) {
+	if authID == "" {
+		return nil, ErrAuthIDRequired
+	}
+	return s.repo.UpsertLessonProgress(ctx, authID, items)
+}
+
+// CourseProgress mengambil ringkasan progress 'authID' di satu course
+// beserta lesson berikutnya untuk tombol "Lanjutkan"
+func (s *CourseService) CourseProgress(ctx context.Context, authID string, courseID uuid.UUID) (*models.CourseProgressSummary, error) {
+	if authID == "" {
+		return nil, ErrAuthIDRequired
+	}
+	return s.repo.GetCourseProgress(ctx, authID, courseID)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wtppaul/course-service/internal/models"
+	"github.com/wtppaul/course-service/internal/repository"
+	"github.com/wtppaul/course-service/internal/utils"
+)
+
+// CreateCategoryInput: ParentID nil = kategori root
+type CreateCategoryInput struct {
+	Name     string
+	ParentID *uuid.UUID
+}
+
+// CreateCategory membuat kategori dengan slug unik. Cek parent, slug dan
+// insert berjalan dalam satu transaksi.
+func (s *CourseService) CreateCategory(ctx context.Context, input CreateCategoryInput) (*models.Category, error) {
+	var category *models.Category
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		// 1. Parent harus ada (jika dikirim)
+		if input.ParentID != nil {
+			if _, err := repo.GetCategoryByID(ctx, *input.ParentID); err != nil {
+				return err
+			}
+		}
+
+		// 2. Slug unik
+		slug, err := utils.GenerateUniqueSlugWith(ctx, input.Name, "category", repo.IsCategorySlugInUse)
+		if err != nil {
+			return err
+		}
+
+		// 3. Simpan
+		category = &models.Category{
+			Name:     input.Name,
+			Slug:     slug,
+			ParentID: input.ParentID,
+		}
+		return repo.CreateCategory(ctx, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory mengganti nama kategori. Slug sengaja tidak diubah agar
+// URL katalog tetap stabil.
+func (s *CourseService) UpdateCategory(ctx context.Context, categoryID uuid.UUID, name string) (*models.Category, error) {
+	var updated *models.Category
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		category, err := repo.GetCategoryByID(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+		category.Name = name
+		updated, err = repo.UpdateCategory(ctx, category)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// MoveCategory memindahkan kategori ke parent lain (nil = jadi root)
+func (s *CourseService) MoveCategory(ctx context.Context, categoryID uuid.UUID, parentID *uuid.UUID) error {
+	return s.repo.MoveCategory(ctx, categoryID, parentID)
+}
+
+// DeleteCategory menghapus kategori tanpa anak
+func (s *CourseService) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	return s.repo.DeleteCategory(ctx, categoryID)
+}
+
+// CategoryTree mengambil seluruh pohon kategori
+func (s *CourseService) CategoryTree(ctx context.Context) ([]models.Category, error) {
+	return s.repo.GetCategoryTree(ctx)
+}
+
+// UpdateCourseCategories mengganti seluruh kategori course (kosong = semua dilepas)
+func (s *CourseService) UpdateCourseCategories(ctx context.Context, courseID uuid.UUID, categoryIDs []uuid.UUID) error {
+	return s.repo.UpdateCourseCategories(ctx, courseID, categoryIDs)
+}
+
+// CreateTag membuat tag dengan slug unik (satu transaksi)
+func (s *CourseService) CreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	var tag *models.Tag
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		slug, err := utils.GenerateUniqueSlugWith(ctx, name, "tag", repo.IsTagSlugInUse)
+		if err != nil {
+			return err
+		}
+
+		tag = &models.Tag{
+			Name: strings.TrimSpace(name),
+			Slug: slug,
+		}
+		return repo.CreateTag(ctx, tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Tags mengambil satu halaman tag beserta jumlah pemakaiannya
+func (s *CourseService) Tags(ctx context.Context, page, limit int) ([]*repository.TagWithUsage, int64, error) {
+	return s.repo.GetTags(ctx, page, limit)
+}
+
+// SuggestTags mencari tag berdasarkan awalan (autocomplete), urut dari
+// yang paling sering dipakai. Awalan kosong = tanpa saran.
+func (s *CourseService) SuggestTags(ctx context.Context, prefix string, limit int) ([]*repository.TagWithUsage, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []*repository.TagWithUsage{}, nil
+	}
+	return s.repo.SuggestTags(ctx, prefix, limit)
+}
+
+// RenameTag mengganti nama tag (slug tetap)
+func (s *CourseService) RenameTag(ctx context.Context, tagID uuid.UUID, name string) (*models.Tag, error) {
+	var renamed *models.Tag
+	err := s.uow.Do(ctx, func(ctx context.Context, repo repository.ICourseRepository) error {
+		tag, err := repo.GetTagByID(ctx, tagID)
+		if err != nil {
+			return err
+		}
+		tag.Name = strings.TrimSpace(name)
+		renamed, err = repo.RenameTag(ctx, tag)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// MergeTags menggabungkan tag duplikat 'sourceID' ke tag kanonik
+// 'targetID'. Mengembalikan jumlah course yang dipindahkan.
+func (s *CourseService) MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) (int64, error) {
+	return s.repo.MergeTags(ctx, sourceID, targetID)
+}
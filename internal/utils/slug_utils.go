@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-	"math/rand"
-	"time"
+	"math/rand/v2"
 	"github.com/wtppaul/course-service/internal/repository"
 )
 
@@ -14,32 +13,39 @@ import (
 var (
 	nonAlphaNumRegex = regexp.MustCompile(`[^a-z0-9\s-]`)
 	spaceRegex       = regexp.MustCompile(`[\s-]+`)
-	// Inisialisasi generator angka acak
-	random = rand.New(rand.NewSource(time.Now().UnixNano()))
 )
 
-// createSlug mengubah "Judul Kursus Keren!" menjadi "judul-kursus-keren"
-func createSlug(title string) string {
+// CreateSlug mengubah "Judul Kursus Keren!" menjadi "judul-kursus-keren"
+func CreateSlug(title string) string {
 	lower := strings.ToLower(title)
 	noSpecial := nonAlphaNumRegex.ReplaceAllString(lower, "")
 	slug := spaceRegex.ReplaceAllString(noSpecial, "-")
 	return strings.Trim(slug, "-")
 }
 
+// SlugInUseFunc mengecek apakah sebuah slug sudah dipakai
+type SlugInUseFunc func(ctx context.Context, slug string) (bool, error)
+
 // GenerateUniqueSlug adalah fungsi utama
 // Ia membuat slug dan memeriksanya ke DB
 func GenerateUniqueSlug(ctx context.Context, title string, repo repository.ICourseRepository) (string, error) {
+	return GenerateUniqueSlugWith(ctx, title, "course", repo.IsSlugInUse)
+}
+
+// GenerateUniqueSlugWith sama seperti GenerateUniqueSlug, tapi untuk
+// tabel lain (kategori, tag, ...) dengan pengecek keunikan sendiri
+func GenerateUniqueSlugWith(ctx context.Context, title, fallback string, inUse SlugInUseFunc) (string, error) {
 	// 1. Buat slug dasar
-	baseSlug := createSlug(title)
+	baseSlug := CreateSlug(title)
 	if baseSlug == "" {
-		baseSlug = "course" // Fallback jika judul hanya berisi simbol
+		baseSlug = fallback // Fallback jika judul hanya berisi simbol
 	}
 	
 	slug := baseSlug
 	
 	// 2. Periksa keunikan
 	for i := 1; i < 10; i++ { // Coba 10 kali
-		exists, err := repo.IsSlugInUse(ctx, slug)
+		exists, err := inUse(ctx, slug)
 		if err != nil {
 			return "", fmt.Errorf("failed to check slug uniqueness: %w", err)
 		}
@@ -54,7 +60,7 @@ func GenerateUniqueSlug(ctx context.Context, title string, repo repository.ICour
 			slug = fmt.Sprintf("%s-%d", baseSlug, i+1) // "judul-2", "judul-3"
 		} else {
 			// "judul-a1b2c"
-			slug = fmt.Sprintf("%s-%s", baseSlug, randomString(5)) 
+			slug = fmt.Sprintf("%s-%s", baseSlug, RandomString(5)) 
 		}
 	}
 	
@@ -62,12 +68,13 @@ func GenerateUniqueSlug(ctx context.Context, title string, repo repository.ICour
 	return "", fmt.Errorf("failed to generate a unique slug for title: %s", title)
 }
 
-// randomString menghasilkan string acak
-func randomString(n int) string {
+// RandomString menghasilkan string acak. Aman dipanggil dari banyak
+// goroutine (generator global math/rand/v2, bukan *rand.Rand bersama).
+func RandomString(n int) string {
     const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
     b := make([]byte, n)
     for i := range b {
-        b[i] = letters[random.Intn(len(letters))]
+        b[i] = letters[rand.IntN(len(letters))]
     }
     return string(b)
 }
\ No newline at end of file
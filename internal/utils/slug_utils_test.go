@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Jalankan dengan -race: RandomString dipakai bersamaan oleh banyak request
+func TestGenerateUniqueSlugConcurrent(t *testing.T) {
+	const workers = 32
+	randomSuffix := regexp.MustCompile(`^judul-kursus-[a-z0-9]{5}$`)
+
+	// Slug dasar & sufiks angka selalu terpakai -> jalur sufiks acak
+	inUse := func(ctx context.Context, slug string) (bool, error) {
+		return !randomSuffix.MatchString(slug), nil
+	}
+
+	var wg sync.WaitGroup
+	slugs := make([]string, workers)
+	errs := make([]error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slugs[i], errs[i] = GenerateUniqueSlugWith(context.Background(), "Judul Kursus", "course", inUse)
+		}()
+	}
+	wg.Wait()
+
+	for i, slug := range slugs {
+		if errs[i] != nil {
+			t.Fatalf("worker %d: %v", i, errs[i])
+		}
+		if !randomSuffix.MatchString(slug) {
+			t.Fatalf("worker %d: slug = %q, want judul-kursus-<5 chars>", i, slug)
+		}
+	}
+}
+
+func TestRandomString(t *testing.T) {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	for _, n := range []int{0, 1, 6, 64} {
+		s := RandomString(n)
+		if len(s) != n {
+			t.Fatalf("len(RandomString(%d)) = %d", n, len(s))
+		}
+		if strings.Trim(s, letters) != "" {
+			t.Fatalf("RandomString(%d) = %q, contains characters outside %q", n, s, letters)
+		}
+	}
+}